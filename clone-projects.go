@@ -2,7 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -11,8 +17,11 @@ import (
 
 type Cloner interface {
 	GetOSWrapper() OSWrapper
-	CloneProjectWithRetry(ctx context.Context, cfg *config.Config, project *Project) error
-	cloneProject(ctx context.Context, cfg *config.Config, project *Project) error
+	CloneProjectWithRetry(ctx context.Context, cfg *config.Config, project *Project) (SyncStatus, error)
+	UpdateProjectWithRetry(ctx context.Context, cfg *config.Config, project *Project) (SyncStatus, error)
+	GetRefs(ctx context.Context, cfg *config.Config, project *Project) (map[string]string, error)
+	GetRemoteRefs(ctx context.Context, cfg *config.Config, project *Project) (map[string]string, error)
+	cloneProject(ctx context.Context, cfg *config.Config, project *Project) (SyncStatus, error)
 }
 
 type GitCloner struct {
@@ -39,13 +48,29 @@ func (c *GitCloner) GetOSWrapper() OSWrapper {
 	return c.osWrapper
 }
 
-func (c *GitCloner) CloneProjectWithRetry(ctx context.Context, cfg *config.Config, project *Project) error {
+func (c *GitCloner) CloneProjectWithRetry(ctx context.Context, cfg *config.Config, project *Project) (SyncStatus, error) {
+	return c.withRetry(ctx, cfg, project, c.cloneProject)
+}
+
+// UpdateProjectWithRetry updates the existing clone of the project regardless of the sync mode.
+func (c *GitCloner) UpdateProjectWithRetry(ctx context.Context, cfg *config.Config, project *Project) (SyncStatus, error) {
+	return c.withRetry(ctx, cfg, project, func(ctx context.Context, cfg *config.Config, project *Project) (SyncStatus, error) {
+		return c.updateProject(ctx, cfg, project, getProjectDir(cfg, project), getCloneURL(cfg, project))
+	})
+}
+
+func (c *GitCloner) withRetry(
+	ctx context.Context,
+	cfg *config.Config,
+	project *Project,
+	fn func(ctx context.Context, cfg *config.Config, project *Project) (SyncStatus, error),
+) (SyncStatus, error) {
 	if cfg == nil {
-		return ErrorNoConfigPassed
+		return SyncStatusFailed, ErrorNoConfigPassed
 	}
 
 	if project == nil {
-		return ErrorNoProjectsPassed
+		return SyncStatusFailed, ErrorNoProjectsPassed
 	}
 
 	maxRetries := cfg.GetMaxRetries()
@@ -55,7 +80,7 @@ func (c *GitCloner) CloneProjectWithRetry(ctx context.Context, cfg *config.Confi
 	if outputDir != "" {
 		err := c.GetOSWrapper().MakeDirAll(outputDir)
 		if err != nil {
-			return &ErrorOutputDirNotCreated{
+			return SyncStatusFailed, &ErrorOutputDirNotCreated{
 				outputDir,
 				err,
 			}
@@ -69,77 +94,313 @@ func (c *GitCloner) CloneProjectWithRetry(ctx context.Context, cfg *config.Confi
 			// log.Printf("Retrying to clone project '%s' (%d/%d)\n", project.pathWithNamespace, attempt+1, maxRetries)
 			select {
 			case <-ctx.Done():
-				return ctx.Err()
+				return SyncStatusFailed, ctx.Err()
 			case <-time.After(retryDelay):
 			}
 		}
 
-		err := c.cloneProject(ctx, cfg, project)
+		status, err := fn(ctx, cfg, project)
 		if err == nil {
-			return nil
+			return status, nil
 		}
 
 		lastErr = err
 
 		if ctx.Err() != nil {
-			return ctx.Err()
+			return SyncStatusFailed, ctx.Err()
 		}
 	}
 
-	return &ErrorFailedAfterRetries{maxRetries, lastErr}
+	return SyncStatusFailed, &ErrorFailedAfterRetries{maxRetries, lastErr}
 }
 
-func (c *GitCloner) cloneProject(ctx context.Context, cfg *config.Config, project *Project) error {
+// GetRefs returns the object names of all refs of the local clone of the project keyed by ref name.
+func (c *GitCloner) GetRefs(ctx context.Context, cfg *config.Config, project *Project) (map[string]string, error) {
 	if cfg == nil {
-		return ErrorNoConfigPassed
+		return nil, ErrorNoConfigPassed
 	}
 
 	if project == nil {
-		return ErrorNoProjectsPassed
+		return nil, ErrorNoProjectsPassed
 	}
 
-	useSSH := cfg.GetUseSSH()
-	token := cfg.GetAccessToken()
-	cloneBare := cfg.GetCloneBare()
-	outputDir := cfg.GetOutputDir()
+	return c.listRefs(ctx, getProjectDir(cfg, project))
+}
 
-	projectDir := project.pathWithNamespace
-	if outputDir != "" {
-		projectDir = outputDir + "/" + projectDir
+// GetRemoteRefs returns the object names of the refs of the remote of the project keyed by ref name,
+// without HEAD and peeled tags.
+func (c *GitCloner) GetRemoteRefs(ctx context.Context, cfg *config.Config, project *Project) (map[string]string, error) {
+	if cfg == nil {
+		return nil, ErrorNoConfigPassed
+	}
+
+	if project == nil {
+		return nil, ErrorNoProjectsPassed
+	}
+
+	output, err := c.osWrapper.ExecuteCommand(ctx, "git", "ls-remote", getCloneURL(cfg, project))
+	if err != nil {
+		return nil, &ErrorFailedToUpdateProject{project.pathWithNamespace, err, output}
+	}
+
+	refs := map[string]string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		objectName, refName, found := strings.Cut(strings.TrimSpace(line), "\t")
+		if !found || refName == "HEAD" || strings.HasSuffix(refName, "^{}") {
+			continue
+		}
+		refs[refName] = objectName
+	}
+
+	return refs, nil
+}
+
+func (c *GitCloner) cloneProject(ctx context.Context, cfg *config.Config, project *Project) (SyncStatus, error) {
+	if cfg == nil {
+		return SyncStatusFailed, ErrorNoConfigPassed
+	}
+
+	if project == nil {
+		return SyncStatusFailed, ErrorNoProjectsPassed
 	}
 
+	projectDir := getProjectDir(cfg, project)
+	url := getCloneURL(cfg, project)
+
 	ok, err := c.osWrapper.IsDirExists(projectDir)
 	if ok || err != nil {
 		if err != nil {
-			return &ErrorDirExistsCheck{projectDir, err}
+			return SyncStatusFailed, &ErrorDirExistsCheck{projectDir, err}
+		}
+
+		if !cfg.GetSyncExisting() {
+			return SyncStatusFailed, ErrorDirExists(projectDir)
 		}
 
-		return ErrorDirExists(projectDir)
+		return c.updateProject(ctx, cfg, project, projectDir, url)
 	}
 
-	cloneURL := project.httpURLToRepo
-	if useSSH {
-		cloneURL = project.sshURLToRepo
+	stagingDir := getStagingDir(cfg, project)
+
+	// A leftover of an interrupted attempt.
+	if err := c.osWrapper.RemoveAll(stagingDir); err != nil {
+		return SyncStatusFailed, &ErrorFailedToCloneProject{project.pathWithNamespace, err, nil}
 	}
 
-	url := cloneURL
-	if !useSSH {
-		url = addTokenToHTTPSURL(cloneURL, token)
+	if err := c.osWrapper.MakeDirAll(filepath.Dir(stagingDir)); err != nil {
+		return SyncStatusFailed, &ErrorFailedToCloneProject{project.pathWithNamespace, err, nil}
 	}
 
 	args := []string{"clone"}
-	if cloneBare {
+	switch cfg.GetCloneMode() {
+	case config.CloneModeBare:
 		args = append(args, "--bare")
+	case config.CloneModeMirror:
+		args = append(args, "--mirror")
 	}
-	args = append(args, url, projectDir)
+	// Forks borrow the objects of the clone of their upstream project instead of fetching and storing them,
+	// it is cloned in full when there is none. The forks are dissociated before the upstream clone is moved
+	// or pruned, see dissociateForks.
+	if project.upstream != nil {
+		if referenceDir, err := filepath.Abs(getProjectDir(cfg, project.upstream)); err == nil {
+			args = append(args, "--reference-if-able", referenceDir)
+		}
+	}
+	args = append(args, url, stagingDir)
 
 	output, err := c.osWrapper.ExecuteCommand(ctx, "git", args...)
 	if err != nil {
-		_ = c.osWrapper.RemoveAll(projectDir)
-		return &ErrorFailedToCloneProject{project.pathWithNamespace, err, output}
+		_ = c.osWrapper.RemoveAll(stagingDir)
+		return SyncStatusFailed, &ErrorFailedToCloneProject{project.pathWithNamespace, err, output}
+	}
+
+	output, err = c.osWrapper.ExecuteCommand(ctx, "git", "-C", stagingDir, "fsck", "--connectivity-only")
+	if err != nil {
+		_ = c.osWrapper.RemoveAll(stagingDir)
+		return SyncStatusFailed, &ErrorIntegrityCheck{project.pathWithNamespace, err, output}
+	}
+
+	if err := c.osWrapper.MakeDirAll(filepath.Dir(projectDir)); err != nil {
+		_ = c.osWrapper.RemoveAll(stagingDir)
+		return SyncStatusFailed, &ErrorProjectRelocation{stagingDir, projectDir, err}
+	}
+
+	if err := c.osWrapper.Rename(stagingDir, projectDir); err != nil {
+		_ = c.osWrapper.RemoveAll(stagingDir)
+		return SyncStatusFailed, &ErrorProjectRelocation{stagingDir, projectDir, err}
+	}
+
+	return SyncStatusCloned, nil
+}
+
+// updateProject brings an existing clone up to date with the remote.
+// Bare clones have no fetch refspec configured, so branches and tags are fetched explicitly,
+// mirror clones get the mirror refspec (re)configured and fetch every ref namespace,
+// working copies are fast-forwarded to their upstream branch.
+func (c *GitCloner) updateProject(ctx context.Context, cfg *config.Config, project *Project, projectDir, url string) (SyncStatus, error) {
+	before, err := c.listRefs(ctx, projectDir)
+	if err != nil {
+		return SyncStatusFailed, err
+	}
+
+	commands := [][]string{
+		{"-C", projectDir, "remote", "set-url", "origin", url},
+	}
+
+	switch cfg.GetCloneMode() {
+	case config.CloneModeMirror:
+		commands = append(commands,
+			[]string{"-C", projectDir, "config", "remote.origin.fetch", "+refs/*:refs/*"},
+			[]string{"-C", projectDir, "config", "remote.origin.mirror", "true"},
+			[]string{"-C", projectDir, "fetch", "--prune", "origin"},
+		)
+	case config.CloneModeBare:
+		commands = append(commands, []string{
+			"-C", projectDir, "fetch", "--prune", "origin",
+			"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*",
+		})
+	default:
+		commands = append(commands, []string{"-C", projectDir, "pull", "--ff-only", "--prune"})
+	}
+
+	for _, args := range commands {
+		output, err := c.osWrapper.ExecuteCommand(ctx, "git", args...)
+		if err != nil {
+			return SyncStatusFailed, &ErrorFailedToUpdateProject{project.pathWithNamespace, err, output}
+		}
+	}
+
+	after, err := c.listRefs(ctx, projectDir)
+	if err != nil {
+		return SyncStatusFailed, err
+	}
+
+	if maps.Equal(before, after) {
+		return SyncStatusUnchanged, nil
+	}
+
+	return SyncStatusUpdated, nil
+}
+
+// listRefs returns the object names of all refs of the repository keyed by ref name.
+func (c *GitCloner) listRefs(ctx context.Context, projectDir string) (map[string]string, error) {
+	output, err := c.osWrapper.ExecuteCommand(ctx, "git", "-C", projectDir, "for-each-ref", "--format=%(objectname) %(refname)")
+	if err != nil {
+		return nil, &ErrorFailedToUpdateProject{projectDir, err, output}
+	}
+
+	refs := map[string]string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		objectName, refName, found := strings.Cut(strings.TrimSpace(line), " ")
+		if !found {
+			continue
+		}
+		refs[refName] = objectName
+	}
+
+	return refs, nil
+}
+
+// matchesRemoteRefs reports whether the refs of a local clone are the refs of the remote mapped by the clone mode:
+// mirror clones hold every ref, bare clones branches and tags, working copies tags and branches as remote-tracking ones.
+func matchesRemoteRefs(mode config.CloneMode, local, remote map[string]string) bool {
+	expected := map[string]string{}
+	for refName, objectName := range remote {
+		switch {
+		case mode == config.CloneModeMirror, strings.HasPrefix(refName, "refs/tags/"):
+			expected[refName] = objectName
+		case !strings.HasPrefix(refName, "refs/heads/"):
+		case mode == config.CloneModeBare:
+			expected[refName] = objectName
+		default:
+			expected["refs/remotes/origin/"+strings.TrimPrefix(refName, "refs/heads/")] = objectName
+		}
+	}
+
+	actual := map[string]string{}
+	for refName, objectName := range local {
+		switch {
+		case mode == config.CloneModeMirror, mode == config.CloneModeBare, strings.HasPrefix(refName, "refs/tags/"):
+			actual[refName] = objectName
+		case strings.HasPrefix(refName, "refs/remotes/origin/") && refName != "refs/remotes/origin/HEAD":
+			actual[refName] = objectName
+		}
+	}
+
+	return maps.Equal(expected, actual)
+}
+
+// getStagingDir returns the directory where the project is cloned before it is moved into the project directory.
+// It is on the same root as the project directory, so archived projects are staged in the archived output directory.
+// The name is unique per project, so concurrent clones never share a staging directory.
+func getStagingDir(cfg *config.Config, project *Project) string {
+	stagingDir := cfg.GetStagingDir()
+	if archivedStagingDir := cfg.GetArchivedStagingDir(); project.archived && archivedStagingDir != "" {
+		stagingDir = archivedStagingDir
+	}
+
+	name := fmt.Sprintf("%d-%s", project.id, strings.ReplaceAll(project.pathWithNamespace, "/", "_"))
+	return filepath.Join(stagingDir, name)
+}
+
+// stagingNamePattern matches the names getStagingDir gives to staged clones.
+var stagingNamePattern = regexp.MustCompile(`^\d+-`)
+
+// cleanStagingDir deletes the clones left in the staging directory by a crashed or killed run.
+// Only entries named like staged clones are deleted, anything else in the directory is kept.
+func cleanStagingDir(osWrapper OSWrapper, stagingDir string) error {
+	entries, err := os.ReadDir(stagingDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() || !stagingNamePattern.MatchString(entry.Name()) {
+			continue
+		}
+
+		if err := osWrapper.RemoveAll(filepath.Join(stagingDir, entry.Name())); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sharedDirName is the directory of shared projects placed under the group they are shared with,
+// "@" is not allowed in GitLab paths, so it never collides with a project or subgroup of the group.
+const sharedDirName = "@shared"
+
+// getProjectDir returns the directory of the project, archived projects are placed into the archived output
+// directory when it is set.
+func getProjectDir(cfg *config.Config, project *Project) string {
+	outputDir := cfg.GetOutputDir()
+	if archivedDir := cfg.GetArchivedOutputDir(); project.archived && archivedDir != "" {
+		outputDir = archivedDir
+	}
+
+	projectDir := project.pathWithNamespace
+	if project.shared && project.group != nil && cfg.GetSharedPlacement() == config.SharedPlacementGroup {
+		projectDir = project.group.fullPath + "/" + sharedDirName + "/" + projectDir
+	}
+
+	if outputDir != "" {
+		projectDir = outputDir + "/" + projectDir
+	}
+
+	return projectDir
+}
+
+func getCloneURL(cfg *config.Config, project *Project) string {
+	if cfg.GetUseSSH() {
+		return project.sshURLToRepo
 	}
 
-	return nil
+	return addTokenToHTTPSURL(project.httpURLToRepo, cfg.GetAccessToken())
 }
 
 func addTokenToHTTPSURL(gitURL, token string) string {
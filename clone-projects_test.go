@@ -3,6 +3,10 @@ package main
 import (
 	"context"
 	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -18,14 +22,26 @@ type mockOSWrapper struct {
 	removeErr   error
 	removedDir  string
 	mkdirErr    error
+	cmdHistory  [][]string
+	cmdHandler  func(args []string) ([]byte, error)
+	renameErr   error
+	renamed     [2]string
+	dirs        map[string]bool
 }
 
-func (m *mockOSWrapper) IsDirExists(_ string) (bool, error) {
+func (m *mockOSWrapper) IsDirExists(path string) (bool, error) {
+	if exists, ok := m.dirs[path]; ok {
+		return exists, m.isDirErr
+	}
 	return m.isDirExists, m.isDirErr
 }
 
 func (m *mockOSWrapper) ExecuteCommand(_ context.Context, name string, args ...string) ([]byte, error) {
 	m.cmdArgs = append([]string{name}, args...)
+	m.cmdHistory = append(m.cmdHistory, m.cmdArgs)
+	if m.cmdHandler != nil {
+		return m.cmdHandler(m.cmdArgs)
+	}
 	return m.cmdOutput, m.cmdErr
 }
 
@@ -34,6 +50,15 @@ func (m *mockOSWrapper) RemoveAll(path string) error {
 	return m.removeErr
 }
 
+func (m *mockOSWrapper) Rename(oldPath, newPath string) error {
+	m.renamed = [2]string{oldPath, newPath}
+	return m.renameErr
+}
+
+func (m *mockOSWrapper) CopyDir(_, _ string) error {
+	return nil
+}
+
 func (m *mockOSWrapper) MakeDirAll(_ string) error {
 	return m.mkdirErr
 }
@@ -113,7 +138,7 @@ func TestGitCloner_cloneProject(t *testing.T) {
 		pathWithNamespace: "repo",
 	}
 	emptyCfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
-		config.CloneBareKey: "false",
+		config.CloneModeKey: "working",
 	}))
 
 	testCases := []struct {
@@ -193,7 +218,15 @@ func TestGitCloner_cloneProject(t *testing.T) {
 			project:   project,
 			osWrapper: &mockOSWrapper{},
 			cfg: config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
-				config.CloneBareKey: "true",
+				config.CloneModeKey: "bare",
+			})),
+		},
+		{
+			name:      "Clone project with mirror clone",
+			project:   project,
+			osWrapper: &mockOSWrapper{},
+			cfg: config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+				config.CloneModeKey: "mirror",
 			})),
 		},
 	}
@@ -201,7 +234,7 @@ func TestGitCloner_cloneProject(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			cloner := NewGitCloner(testCase.osWrapper)
-			err := cloner.cloneProject(context.Background(), testCase.cfg, testCase.project)
+			_, err := cloner.cloneProject(context.Background(), testCase.cfg, testCase.project)
 			if err != nil {
 				if testCase.expectedError != nil {
 					if errors.Is(err, testCase.expectedError) {
@@ -230,8 +263,9 @@ func TestGitCloner_cloneProject(t *testing.T) {
 				t.Fatalf("expected success, got error: %v", err)
 			}
 
-			if testCase.osWrapper.cmdArgs[0] != "git" || testCase.osWrapper.cmdArgs[1] != "clone" {
-				t.Errorf("expected git clone command, got: %v", testCase.osWrapper.cmdArgs)
+			cloneArgs := testCase.osWrapper.cmdHistory[0]
+			if cloneArgs[0] != "git" || cloneArgs[1] != "clone" {
+				t.Errorf("expected git clone command, got: %v", cloneArgs)
 			}
 
 			expectedURL := testCase.project.sshURLToRepo
@@ -239,18 +273,28 @@ func TestGitCloner_cloneProject(t *testing.T) {
 				expectedURL = addTokenToHTTPSURL(testCase.project.httpURLToRepo, testCase.cfg.GetAccessToken())
 			}
 
-			cloneBare := testCase.cfg.GetCloneBare()
 			offset := 0
-			if cloneBare {
+			if cloneMode := testCase.cfg.GetCloneMode(); cloneMode != config.CloneModeWorking {
 				offset = 1
 
-				if testCase.osWrapper.cmdArgs[2] != "--bare" {
-					t.Errorf("expected '--bare' flag, got: %s", testCase.osWrapper.cmdArgs[2])
+				expectedFlag := "--" + string(cloneMode)
+				if cloneArgs[2] != expectedFlag {
+					t.Errorf("expected '%s' flag, got: %s", expectedFlag, cloneArgs[2])
 				}
 			}
 
-			if testCase.osWrapper.cmdArgs[offset+2] != expectedURL {
-				t.Errorf("expected URL %s, got: %s", expectedURL, testCase.osWrapper.cmdArgs[offset+2])
+			if cloneArgs[offset+2] != expectedURL {
+				t.Errorf("expected URL %s, got: %s", expectedURL, cloneArgs[offset+2])
+			}
+
+			expectedStagingDir := getStagingDir(testCase.cfg, testCase.project)
+			if cloneArgs[offset+3] != expectedStagingDir {
+				t.Errorf("expected staging path %s, got: %s", expectedStagingDir, cloneArgs[offset+3])
+			}
+
+			expectedCheck := []string{"git", "-C", expectedStagingDir, "fsck", "--connectivity-only"}
+			if len(testCase.osWrapper.cmdHistory) != 2 || !slices.Equal(testCase.osWrapper.cmdHistory[1], expectedCheck) {
+				t.Errorf("expected integrity check %v, got: %v", expectedCheck, testCase.osWrapper.cmdHistory)
 			}
 
 			expectedPath := testCase.project.pathWithNamespace
@@ -258,8 +302,64 @@ func TestGitCloner_cloneProject(t *testing.T) {
 				expectedPath = testCase.cfg.GetOutputDir() + "/" + expectedPath
 			}
 
-			if testCase.osWrapper.cmdArgs[offset+3] != expectedPath {
-				t.Errorf("expected project path %s, got: %s", testCase.project.pathWithNamespace, testCase.osWrapper.cmdArgs[offset+3])
+			if testCase.osWrapper.renamed != [2]string{expectedStagingDir, expectedPath} {
+				t.Errorf("expected staging directory moved to %s, got: %v", expectedPath, testCase.osWrapper.renamed)
+			}
+		})
+	}
+}
+
+func TestGitCloner_cloneProject_StagingFailures(t *testing.T) {
+	project := &Project{
+		id:                1,
+		httpURLToRepo:     "https://gitlab.com/repo.git",
+		pathWithNamespace: "group/repo",
+	}
+	cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+		config.OutputDirKey: "out",
+	}))
+	stagingDir := getStagingDir(cfg, project)
+
+	testCases := []struct {
+		name          string
+		osWrapper     *mockOSWrapper
+		expectedError error
+	}{
+		{
+			name: "Integrity check failed",
+			osWrapper: &mockOSWrapper{
+				cmdHandler: func(args []string) ([]byte, error) {
+					if args[3] == "fsck" {
+						return []byte("broken link"), errors.New("exit status 1")
+					}
+					return nil, nil
+				},
+			},
+			expectedError: &ErrorIntegrityCheck{project.pathWithNamespace, errors.New("exit status 1"), []byte("broken link")},
+		},
+		{
+			name: "Move into place failed",
+			osWrapper: &mockOSWrapper{
+				renameErr: errors.New("cross-device link"),
+			},
+			expectedError: &ErrorProjectRelocation{stagingDir, "out/group/repo", errors.New("cross-device link")},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cloner := NewGitCloner(testCase.osWrapper)
+			status, err := cloner.cloneProject(context.Background(), cfg, project)
+			if err == nil || err.Error() != testCase.expectedError.Error() {
+				t.Fatalf("expected error '%v', got: '%v'", testCase.expectedError, err)
+			}
+
+			if status != SyncStatusFailed {
+				t.Errorf("expected failed status, got: %s", status)
+			}
+
+			if testCase.osWrapper.removedDir != stagingDir {
+				t.Errorf("expected staging directory %s to be removed, got: %s", stagingDir, testCase.osWrapper.removedDir)
 			}
 		})
 	}
@@ -337,7 +437,7 @@ func TestGitCloner_CloneProjectWithRetry(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			cloner := NewGitCloner(testCase.osWrapper)
-			err := cloner.CloneProjectWithRetry(context.Background(), testCase.cfg, testCase.project)
+			_, err := cloner.CloneProjectWithRetry(context.Background(), testCase.cfg, testCase.project)
 			if err == nil {
 				return
 			}
@@ -388,7 +488,7 @@ func TestGitCloner_CloneProjectWithRetry_ContextCancel(t *testing.T) {
 		cmdErr: errors.New("failed"),
 	})
 
-	err := cloner.CloneProjectWithRetry(ctx, cfg, project)
+	_, err := cloner.CloneProjectWithRetry(ctx, cfg, project)
 	if err == nil {
 		t.Fatalf("expected context canceled error, got nil")
 	}
@@ -397,3 +497,356 @@ func TestGitCloner_CloneProjectWithRetry_ContextCancel(t *testing.T) {
 		t.Fatalf("expected context canceled error, got: %v", err)
 	}
 }
+
+func TestGitCloner_updateProject(t *testing.T) {
+	project := &Project{
+		httpURLToRepo:     "https://gitlab.com/repo.git",
+		sshURLToRepo:      "git://gitlab.com:repo.git",
+		pathWithNamespace: "repo",
+	}
+
+	refsHandler := func(before, after string) func(args []string) ([]byte, error) {
+		calls := 0
+		return func(args []string) ([]byte, error) {
+			if !slices.Contains(args, "for-each-ref") {
+				return nil, nil
+			}
+			calls++
+			if calls == 1 {
+				return []byte(before), nil
+			}
+			return []byte(after), nil
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		cfg            map[string]string
+		handler        func(args []string) ([]byte, error)
+		expectedStatus SyncStatus
+		expectedErr    bool
+		expectedCmd    []string
+	}{
+		{
+			name: "Existing directory without sync mode",
+			cfg: map[string]string{
+				config.SyncExistingKey: "false",
+			},
+			expectedStatus: SyncStatusFailed,
+			expectedErr:    true,
+		},
+		{
+			name: "Bare repository is fetched with prune",
+			cfg: map[string]string{
+				config.SyncExistingKey: "true",
+				config.CloneModeKey:    "bare",
+			},
+			handler:        refsHandler("aaa refs/heads/main\n", "bbb refs/heads/main\n"),
+			expectedStatus: SyncStatusUpdated,
+			expectedCmd: []string{
+				"git", "-C", "repo", "fetch", "--prune", "origin",
+				"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*",
+			},
+		},
+		{
+			name: "Working copy is fast-forwarded",
+			cfg: map[string]string{
+				config.SyncExistingKey: "true",
+				config.CloneModeKey:    "working",
+			},
+			handler:        refsHandler("aaa refs/heads/main\n", "aaa refs/heads/main\n"),
+			expectedStatus: SyncStatusUnchanged,
+			expectedCmd:    []string{"git", "-C", "repo", "pull", "--ff-only", "--prune"},
+		},
+		{
+			name: "Mirror repository fetches every ref",
+			cfg: map[string]string{
+				config.SyncExistingKey: "true",
+				config.CloneModeKey:    "mirror",
+			},
+			handler:        refsHandler("aaa refs/heads/main\n", "aaa refs/heads/main\nccc refs/merge-requests/1/head\n"),
+			expectedStatus: SyncStatusUpdated,
+			expectedCmd:    []string{"git", "-C", "repo", "config", "remote.origin.fetch", "+refs/*:refs/*"},
+		},
+		{
+			name: "Failed to fetch",
+			cfg: map[string]string{
+				config.SyncExistingKey: "true",
+			},
+			handler: func(args []string) ([]byte, error) {
+				if slices.Contains(args, "fetch") {
+					return []byte("fatal"), errors.New("failed")
+				}
+				return nil, nil
+			},
+			expectedStatus: SyncStatusFailed,
+			expectedErr:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			osWrapper := &mockOSWrapper{
+				isDirExists: true,
+				cmdHandler:  testCase.handler,
+			}
+			cfg := config.NewConfig(config.NewMemoryEnvLoader(testCase.cfg))
+
+			cloner := NewGitCloner(osWrapper)
+			status, err := cloner.cloneProject(context.Background(), cfg, project)
+
+			if status != testCase.expectedStatus {
+				t.Errorf("expected status %s, got %s", testCase.expectedStatus, status)
+			}
+
+			if testCase.expectedErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if osWrapper.removedDir != "" {
+					t.Errorf("existing directory must not be removed, removed %s", osWrapper.removedDir)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			found := slices.ContainsFunc(osWrapper.cmdHistory, func(args []string) bool {
+				return slices.Equal(args, testCase.expectedCmd)
+			})
+			if !found {
+				t.Errorf("expected command %v, got %v", testCase.expectedCmd, osWrapper.cmdHistory)
+			}
+		})
+	}
+}
+
+func TestGetProjectDir(t *testing.T) {
+	group := &Group{id: 1, fullPath: "root/team"}
+
+	tests := []struct {
+		name      string
+		placement string
+		project   *Project
+		expected  string
+	}{
+		{"own project", "group", &Project{pathWithNamespace: "root/team/app", group: group}, "out/root/team/app"},
+		{"shared project in namespace", "namespace", &Project{pathWithNamespace: "other/lib", group: group, shared: true}, "out/other/lib"},
+		{"shared project in group", "group", &Project{pathWithNamespace: "other/lib", group: group, shared: true}, "out/root/team/@shared/other/lib"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+				config.OutputDirKey:       "out",
+				config.SharedPlacementKey: test.placement,
+			}))
+
+			if result := getProjectDir(cfg, test.project); result != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestGetProjectDir_Archived(t *testing.T) {
+	project := &Project{pathWithNamespace: "root/app", archived: true}
+
+	cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+		config.OutputDirKey: "out",
+	}))
+	if result := getProjectDir(cfg, project); result != "out/root/app" {
+		t.Errorf("expected %s, got %s", "out/root/app", result)
+	}
+
+	cfg = config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+		config.OutputDirKey:         "out",
+		config.ArchivedOutputDirKey: "archived",
+	}))
+	if result := getProjectDir(cfg, project); result != "out/archived/root/app" {
+		t.Errorf("expected %s, got %s", "out/archived/root/app", result)
+	}
+	if result := getProjectDir(cfg, &Project{pathWithNamespace: "root/lib"}); result != "out/root/lib" {
+		t.Errorf("expected %s, got %s", "out/root/lib", result)
+	}
+}
+
+func TestGitCloner_cloneProject_ArchivedOutputDir(t *testing.T) {
+	cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+		config.OutputDirKey:         "/backup",
+		config.ArchivedOutputDirKey: "/cold/backup",
+	}))
+	project := &Project{
+		id:                1,
+		httpURLToRepo:     "https://gitlab.com/group/repo.git",
+		pathWithNamespace: "group/repo",
+		archived:          true,
+	}
+
+	stagingDir := getStagingDir(cfg, project)
+	if stagingDir != "/cold/backup/.staging/1-group_repo" {
+		t.Errorf("expected staging directory in the archived output directory, got %s", stagingDir)
+	}
+
+	osWrapper := &mockOSWrapper{}
+	if _, err := NewGitCloner(osWrapper).cloneProject(context.Background(), cfg, project); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if osWrapper.renamed != [2]string{stagingDir, "/cold/backup/group/repo"} {
+		t.Errorf("expected move from %s to %s, got: %v", stagingDir, "/cold/backup/group/repo", osWrapper.renamed)
+	}
+}
+
+func TestGitCloner_cloneProject_Fork(t *testing.T) {
+	cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+		config.OutputDirKey: "/backup",
+	}))
+	upstream := &Project{pathWithNamespace: "org/app"}
+	fork := &Project{
+		id:                2,
+		httpURLToRepo:     "https://gitlab.com/alice/app.git",
+		pathWithNamespace: "alice/app",
+		forkedFromID:      1,
+		upstream:          upstream,
+	}
+
+	osWrapper := &mockOSWrapper{}
+	if _, err := NewGitCloner(osWrapper).cloneProject(context.Background(), cfg, fork); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"git", "clone", "--bare", "--reference-if-able", "/backup/org/app",
+		"https://gitlab.com/alice/app.git", getStagingDir(cfg, fork),
+	}
+	if !slices.Equal(osWrapper.cmdHistory[0], expected) {
+		t.Errorf("expected command %v, got: %v", expected, osWrapper.cmdHistory[0])
+	}
+}
+
+func TestGitCloner_GetRemoteRefs(t *testing.T) {
+	cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
+	project := &Project{httpURLToRepo: "https://gitlab.com/group/repo.git", pathWithNamespace: "group/repo"}
+
+	osWrapper := &mockOSWrapper{
+		cmdOutput: []byte("aaa\tHEAD\naaa\trefs/heads/main\nbbb\trefs/tags/v1\nccc\trefs/tags/v1^{}\n"),
+	}
+	refs, err := NewGitCloner(osWrapper).GetRemoteRefs(context.Background(), cfg, project)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{"refs/heads/main": "aaa", "refs/tags/v1": "bbb"}
+	if !maps.Equal(refs, expected) {
+		t.Errorf("expected refs %v, got %v", expected, refs)
+	}
+	if !slices.Equal(osWrapper.cmdArgs, []string{"git", "ls-remote", "https://gitlab.com/group/repo.git"}) {
+		t.Errorf("unexpected command: %v", osWrapper.cmdArgs)
+	}
+}
+
+func TestMatchesRemoteRefs(t *testing.T) {
+	remote := map[string]string{
+		"refs/heads/main":            "aaa",
+		"refs/tags/v1":               "bbb",
+		"refs/merge-requests/1/head": "ccc",
+	}
+
+	tests := []struct {
+		name     string
+		mode     config.CloneMode
+		local    map[string]string
+		expected bool
+	}{
+		{
+			name:     "bare",
+			mode:     config.CloneModeBare,
+			local:    map[string]string{"refs/heads/main": "aaa", "refs/tags/v1": "bbb"},
+			expected: true,
+		},
+		{
+			name:  "bare behind",
+			mode:  config.CloneModeBare,
+			local: map[string]string{"refs/heads/main": "000", "refs/tags/v1": "bbb"},
+		},
+		{
+			name: "mirror",
+			mode: config.CloneModeMirror,
+			local: map[string]string{
+				"refs/heads/main":            "aaa",
+				"refs/tags/v1":               "bbb",
+				"refs/merge-requests/1/head": "ccc",
+			},
+			expected: true,
+		},
+		{
+			name:  "mirror without merge requests",
+			mode:  config.CloneModeMirror,
+			local: map[string]string{"refs/heads/main": "aaa", "refs/tags/v1": "bbb"},
+		},
+		{
+			name: "working",
+			mode: config.CloneModeWorking,
+			local: map[string]string{
+				"refs/heads/main":          "aaa",
+				"refs/remotes/origin/HEAD": "aaa",
+				"refs/remotes/origin/main": "aaa",
+				"refs/tags/v1":             "bbb",
+			},
+			expected: true,
+		},
+		{
+			name: "working with a deleted remote branch",
+			mode: config.CloneModeWorking,
+			local: map[string]string{
+				"refs/remotes/origin/main":    "aaa",
+				"refs/remotes/origin/feature": "ddd",
+				"refs/tags/v1":                "bbb",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := matchesRemoteRefs(test.mode, test.local, remote); result != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestCleanStagingDir(t *testing.T) {
+	stagingDir := t.TempDir()
+
+	staged := filepath.Join(stagingDir, "12-group_project")
+	kept := []string{filepath.Join(stagingDir, "group"), filepath.Join(stagingDir, "12-notes.txt")}
+	if err := os.MkdirAll(filepath.Join(staged, "objects"), 0o755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.MkdirAll(kept[0], 0o755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(kept[1], nil, 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := cleanStagingDir(GetDefaultOSWrapper(), stagingDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(staged); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected staged clone to be deleted, got %v", err)
+	}
+	for _, path := range kept {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to be kept: %v", path, err)
+		}
+	}
+
+	if err := cleanStagingDir(GetDefaultOSWrapper(), filepath.Join(stagingDir, "missing")); err != nil {
+		t.Errorf("expected a missing staging directory to be ignored, got %v", err)
+	}
+}
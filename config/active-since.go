@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errInvalidActiveSince is returned for values which are neither a date nor a relative window.
+var errInvalidActiveSince = errors.New("invalid active since value")
+
+// relativeUnits are the units of relative windows of ActiveSinceKey besides time.ParseDuration ones.
+var relativeUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// parseActiveSince returns the moment of the value relative to now: a date (2006-01-02),
+// a time (RFC 3339), a number of days or weeks (90d, 12w) or a Go duration (36h).
+// It returns the zero time for an empty value.
+func parseActiveSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if since, err := time.Parse(layout, value); err == nil {
+			return since, nil
+		}
+	}
+
+	for suffix, unit := range relativeUnits {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			count, err := strconv.Atoi(number)
+			if err != nil || count < 0 {
+				return time.Time{}, errInvalidActiveSince
+			}
+
+			return now.Add(-time.Duration(count) * unit), nil
+		}
+	}
+
+	window, err := time.ParseDuration(value)
+	if err != nil || window < 0 {
+		return time.Time{}, errInvalidActiveSince
+	}
+
+	return now.Add(-window), nil
+}
+
+// extractActiveSince returns the moment of ActiveSinceKey, invalid values are reported by Validate.
+func extractActiveSince(loader EnvLoader) time.Time {
+	since, _ := parseActiveSince(loader.Get(ActiveSinceKey), time.Now())
+	return since
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseActiveSince(t *testing.T) {
+	now := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+		invalid  bool
+	}{
+		{"", time.Time{}, false},
+		{"2024-01-31", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), false},
+		{"2024-01-31T10:00:00+02:00", time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC), false},
+		{"90d", now.AddDate(0, 0, -90), false},
+		{" 2w ", now.AddDate(0, 0, -14), false},
+		{"36h", now.Add(-36 * time.Hour), false},
+		{"recently", time.Time{}, true},
+		{"-5d", time.Time{}, true},
+		{"1.5d", time.Time{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			since, err := parseActiveSince(test.value, now)
+			if (err != nil) != test.invalid {
+				t.Fatalf("expected invalid %t, got error %v", test.invalid, err)
+			}
+
+			if !since.Equal(test.expected) {
+				t.Errorf("expected %s, got %s", test.expected, since)
+			}
+		})
+	}
+}
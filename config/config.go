@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"sync"
@@ -10,16 +12,52 @@ import (
 
 // Config holds the configuration for the GitLab repository downloader.
 type Config struct {
-	groupIDs     []string
-	skipGroupIDs []string
-	gitLabURL    string
-	accessToken  string
-	outputDir    string
-	retryDelay   time.Duration
-	maxWorkers   int
-	maxRetries   int
-	useSSH       bool
-	cloneBare    bool
+	groupIDs            []string
+	skipGroupIDs        []string
+	userIDs             []string
+	projectIDs          []string
+	membership          bool
+	minAccessLevel      int
+	adminMode           bool
+	withShared          bool
+	sharedPlacement     SharedPlacement
+	discoveryStrategy   DiscoveryStrategy
+	apiBackend          APIBackend
+	includePaths        []*PathPattern
+	excludePaths        []*PathPattern
+	includeVisibility   []Visibility
+	excludeVisibility   []Visibility
+	includeTopics       []string
+	excludeTopics       []string
+	archived            ArchivedPolicy
+	archivedOutputDir   string
+	forks               ForksMode
+	activeSince         time.Time
+	statistics          bool
+	maxRepoSize         int64
+	cloneOrder          CloneOrder
+	instanceName        string
+	instances           []*Config
+	gitLabURL           string
+	accessToken         string
+	outputDir           string
+	stateFile           string
+	journalFile         string
+	resume              bool
+	retryDelay          time.Duration
+	maxWorkers          int
+	maxRetries          int
+	useSSH              bool
+	cloneMode           CloneMode
+	syncExisting        bool
+	pruneMode           PruneMode
+	quarantineDir       string
+	quarantineRetention time.Duration
+	stagingDir          string
+	maxFailurePercent   int
+	dryRun              bool
+	listFormat          ListFormat
+	invalidValues       []error
 }
 
 func extractGroupIDs(groupIDs string) []string {
@@ -30,6 +68,47 @@ func extractGroupIDs(groupIDs string) []string {
 	return slices.Compact(cleaned)
 }
 
+// extractProjectIDs returns the projects of ProjectIDsKey and ProjectIDsFileKey without duplicates,
+// an unreadable file is reported by Validate.
+func extractProjectIDs(loader EnvLoader) []string {
+	projectIDs := extractGroupIDs(loader.Get(ProjectIDsKey))
+
+	if path := loader.Get(ProjectIDsFileKey); path != "" {
+		if lines, err := readListFile(path); err == nil {
+			projectIDs = append(projectIDs, lines...)
+		}
+	}
+
+	seen := map[string]struct{}{}
+
+	return slices.DeleteFunc(projectIDs, func(projectID string) bool {
+		if _, ok := seen[projectID]; ok {
+			return true
+		}
+		seen[projectID] = struct{}{}
+		return false
+	})
+}
+
+// readListFile returns the non-empty lines of the file, lines starting with # are comments.
+func readListFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
 func NewConfig(loaders ...EnvLoader) *Config {
 	var loader EnvLoader
 
@@ -44,16 +123,51 @@ func NewConfig(loaders ...EnvLoader) *Config {
 	loader.Load()
 
 	return &Config{
-		gitLabURL:    loader.Get(GitlabURLKey, DefaultGitlabURL),
-		accessToken:  loader.Get(GitlabTokenKey),
-		outputDir:    loader.Get(OutputDirKey, DefaultOutputDir),
-		useSSH:       loader.Get(UseSSHKey, DefaultUseSSH) == "true",
-		cloneBare:    loader.Get(CloneBareKey, DefaultCloneBare) == "true",
-		groupIDs:     extractGroupIDs(loader.Get(GroupIDsKey)),
-		skipGroupIDs: extractGroupIDs(loader.Get(SkipGroupIDsKey)),
-		maxWorkers:   loader.GetInt(MaxWorkersKey, DefaultMaxWorkers),
-		maxRetries:   loader.GetInt(MaxRetriesKey, DefaultMaxRetries),
-		retryDelay:   time.Duration(loader.GetInt(RetryDelayKey, DefaultRetryDelay)) * time.Second,
+		gitLabURL:           loader.Get(GitlabURLKey, DefaultGitlabURL),
+		accessToken:         loader.Get(GitlabTokenKey),
+		outputDir:           loader.Get(OutputDirKey, DefaultOutputDir),
+		stateFile:           loader.Get(StateFileKey, DefaultStateFile),
+		journalFile:         loader.Get(JournalFileKey, DefaultJournalFile),
+		resume:              loader.Get(ResumeKey, DefaultResume) == "true",
+		useSSH:              loader.Get(UseSSHKey, DefaultUseSSH) == "true",
+		cloneMode:           extractCloneMode(loader),
+		syncExisting:        loader.Get(SyncExistingKey, DefaultSyncExisting) == "true",
+		pruneMode:           extractPruneMode(loader),
+		quarantineDir:       loader.Get(QuarantineDirKey, DefaultQuarantineDir),
+		quarantineRetention: time.Duration(loader.GetInt(QuarantineRetentionKey, DefaultQuarantineRetention)) * 24 * time.Hour,
+		stagingDir:          loader.Get(StagingDirKey, DefaultStagingDir),
+		maxFailurePercent:   loader.GetInt(MaxFailurePercentKey, DefaultMaxFailurePercent),
+		dryRun:              loader.Get(DryRunKey, DefaultDryRun) == "true",
+		listFormat:          extractListFormat(loader),
+		invalidValues:       findInvalidValues(loader),
+		groupIDs:            extractGroupIDs(loader.Get(GroupIDsKey)),
+		skipGroupIDs:        extractGroupIDs(loader.Get(SkipGroupIDsKey)),
+		userIDs:             extractGroupIDs(loader.Get(UserIDsKey)),
+		projectIDs:          extractProjectIDs(loader),
+		membership:          loader.Get(MembershipKey, DefaultMembership) == "true",
+		minAccessLevel:      extractAccessLevel(loader.Get(MinAccessLevelKey)),
+		adminMode:           loader.Get(AdminModeKey, DefaultAdminMode) == "true",
+		withShared:          loader.Get(WithSharedKey, DefaultWithShared) == "true",
+		sharedPlacement:     extractSharedPlacement(loader),
+		discoveryStrategy:   extractDiscoveryStrategy(loader),
+		apiBackend:          extractAPIBackend(loader),
+		includePaths:        extractPathPatterns(loader.Get(IncludePathsKey)),
+		excludePaths:        extractPathPatterns(loader.Get(ExcludePathsKey)),
+		includeVisibility:   extractVisibilities(loader.Get(IncludeVisibilityKey)),
+		excludeVisibility:   extractVisibilities(loader.Get(ExcludeVisibilityKey)),
+		includeTopics:       extractTopics(loader.Get(IncludeTopicsKey)),
+		excludeTopics:       extractTopics(loader.Get(ExcludeTopicsKey)),
+		archived:            extractArchivedPolicy(loader),
+		archivedOutputDir:   loader.Get(ArchivedOutputDirKey),
+		forks:               extractForksMode(loader),
+		activeSince:         extractActiveSince(loader),
+		statistics:          loader.Get(StatisticsKey, DefaultStatistics) == "true",
+		maxRepoSize:         extractMaxRepoSize(loader),
+		cloneOrder:          extractCloneOrder(loader),
+		instances:           extractInstances(loader),
+		maxWorkers:          loader.GetInt(MaxWorkersKey, DefaultMaxWorkers),
+		maxRetries:          loader.GetInt(MaxRetriesKey, DefaultMaxRetries),
+		retryDelay:          time.Duration(loader.GetInt(RetryDelayKey, DefaultRetryDelay)) * time.Second,
 	}
 }
 
@@ -69,6 +183,28 @@ func (c *Config) GetOutputDir() string {
 	return c.outputDir
 }
 
+// GetStateFile returns the path of the state file, relative paths are resolved against the output directory.
+func (c *Config) GetStateFile() string {
+	if filepath.IsAbs(c.stateFile) {
+		return c.stateFile
+	}
+
+	return filepath.Join(c.outputDir, c.stateFile)
+}
+
+// GetJournalFile returns the path of the run journal, relative paths are resolved against the output directory.
+func (c *Config) GetJournalFile() string {
+	if filepath.IsAbs(c.journalFile) {
+		return c.journalFile
+	}
+
+	return filepath.Join(c.outputDir, c.journalFile)
+}
+
+func (c *Config) GetResume() bool {
+	return c.resume
+}
+
 func (c *Config) GetGroupIDs() []string {
 	return c.groupIDs
 }
@@ -77,6 +213,120 @@ func (c *Config) GetSkipGroupIDs() []string {
 	return c.skipGroupIDs
 }
 
+// GetProjectIDs returns the IDs or paths of projects which are cloned regardless of their groups.
+func (c *Config) GetProjectIDs() []string {
+	return c.projectIDs
+}
+
+// GetMembership reports whether all projects the token is a member of are cloned.
+func (c *Config) GetMembership() bool {
+	return c.membership
+}
+
+// GetMinAccessLevel returns the minimum access level of member projects, 0 means any level.
+func (c *Config) GetMinAccessLevel() int {
+	return c.minAccessLevel
+}
+
+// GetAdminMode reports whether all projects of the instance are listed instead of walking all groups.
+func (c *Config) GetAdminMode() bool {
+	return c.adminMode
+}
+
+// GetWithShared reports whether projects shared with the groups are cloned with the projects of the groups.
+func (c *Config) GetWithShared() bool {
+	return c.withShared
+}
+
+func (c *Config) GetSharedPlacement() SharedPlacement {
+	return c.sharedPlacement
+}
+
+// GetDiscoveryStrategy returns how the projects of the groups are listed.
+func (c *Config) GetDiscoveryStrategy() DiscoveryStrategy {
+	return c.discoveryStrategy
+}
+
+// GetAPIBackend returns which GitLab API lists subgroups and projects of groups.
+func (c *Config) GetAPIBackend() APIBackend {
+	return c.apiBackend
+}
+
+// GetIncludePaths returns the patterns of which a project path must match one, all projects are included without them.
+func (c *Config) GetIncludePaths() []*PathPattern {
+	return c.includePaths
+}
+
+// GetExcludePaths returns the patterns of project paths which are not cloned.
+func (c *Config) GetExcludePaths() []*PathPattern {
+	return c.excludePaths
+}
+
+// GetIncludeVisibility returns the visibility levels of which a project must have one, all levels are included without them.
+func (c *Config) GetIncludeVisibility() []Visibility {
+	return c.includeVisibility
+}
+
+// GetExcludeVisibility returns the visibility levels of projects which are not cloned.
+func (c *Config) GetExcludeVisibility() []Visibility {
+	return c.excludeVisibility
+}
+
+// GetIncludeTopics returns the topics of which a project must have one, all projects are included without them.
+func (c *Config) GetIncludeTopics() []string {
+	return c.includeTopics
+}
+
+// GetExcludeTopics returns the topics of projects which are not cloned.
+func (c *Config) GetExcludeTopics() []string {
+	return c.excludeTopics
+}
+
+// GetArchived returns which of archived and active projects are cloned.
+func (c *Config) GetArchived() ArchivedPolicy {
+	return c.archived
+}
+
+// GetArchivedOutputDir returns the output directory of archived projects, relative paths are resolved
+// against the output directory. It is empty when archived projects are cloned into the output directory.
+func (c *Config) GetArchivedOutputDir() string {
+	if c.archivedOutputDir == "" || filepath.IsAbs(c.archivedOutputDir) {
+		return c.archivedOutputDir
+	}
+
+	return filepath.Join(c.outputDir, c.archivedOutputDir)
+}
+
+// GetForks returns how forks are cloned.
+func (c *Config) GetForks() ForksMode {
+	return c.forks
+}
+
+// GetActiveSince returns the moment of the last activity projects must have to be cloned, zero means any.
+func (c *Config) GetActiveSince() time.Time {
+	return c.activeSince
+}
+
+// GetStatistics reports whether the repository sizes of projects are fetched,
+// which is needed for the size limit and ordering by size too.
+func (c *Config) GetStatistics() bool {
+	return c.statistics || c.maxRepoSize > 0 || c.cloneOrder != CloneOrderDiscovery
+}
+
+// GetMaxRepoSize returns the size in bytes of the largest repository which is cloned, 0 means no limit.
+func (c *Config) GetMaxRepoSize() int64 {
+	return c.maxRepoSize
+}
+
+func (c *Config) GetCloneOrder() CloneOrder {
+	return c.cloneOrder
+}
+
+// GetUserIDs returns the users (IDs, usernames or CurrentUserAlias) whose personal projects are cloned.
+func (c *Config) GetUserIDs() []string {
+	return c.userIDs
+}
+
 func (c *Config) GetRetryDelay() time.Duration {
 	return c.retryDelay
 }
@@ -93,8 +343,78 @@ func (c *Config) GetUseSSH() bool {
 	return c.useSSH
 }
 
-func (c *Config) GetCloneBare() bool {
-	return c.cloneBare
+func (c *Config) GetCloneMode() CloneMode {
+	return c.cloneMode
+}
+
+func (c *Config) GetSyncExisting() bool {
+	return c.syncExisting
+}
+
+func (c *Config) GetPruneMode() PruneMode {
+	return c.pruneMode
+}
+
+// GetQuarantineDir returns the directory for pruned repositories, relative paths are resolved against the output directory.
+func (c *Config) GetQuarantineDir() string {
+	if filepath.IsAbs(c.quarantineDir) {
+		return c.quarantineDir
+	}
+
+	return filepath.Join(c.outputDir, c.quarantineDir)
+}
+
+func (c *Config) GetQuarantineRetention() time.Duration {
+	return c.quarantineRetention
+}
+
+// GetStagingDir returns the directory where new clones are made before they are moved into place,
+// relative paths are resolved against the output directory.
+func (c *Config) GetStagingDir() string {
+	if filepath.IsAbs(c.stagingDir) {
+		return c.stagingDir
+	}
+
+	return filepath.Join(c.outputDir, c.stagingDir)
+}
+
+// GetArchivedStagingDir returns the staging directory of archived projects inside the archived output directory,
+// so their clones are moved into place within one filesystem. An absolute staging directory is replaced by
+// the default one there. It is empty when archived projects are cloned into the output directory.
+func (c *Config) GetArchivedStagingDir() string {
+	archivedDir := c.GetArchivedOutputDir()
+	if archivedDir == "" {
+		return ""
+	}
+
+	if filepath.IsAbs(c.stagingDir) {
+		return filepath.Join(archivedDir, DefaultStagingDir)
+	}
+
+	return filepath.Join(archivedDir, c.stagingDir)
+}
+
+// GetStagingDirs returns the staging directories of the output directory and of the archived output directory.
+func (c *Config) GetStagingDirs() []string {
+	if archivedStagingDir := c.GetArchivedStagingDir(); archivedStagingDir != "" {
+		return []string{c.GetStagingDir(), archivedStagingDir}
+	}
+
+	return []string{c.GetStagingDir()}
+}
+
+// GetMaxFailurePercent returns the percentage of failed projects a run tolerates before it is reported as failed.
+func (c *Config) GetMaxFailurePercent() int {
+	return c.maxFailurePercent
+}
+
+// GetDryRun reports whether groups and projects are only listed without cloning anything.
+func (c *Config) GetDryRun() bool {
+	return c.dryRun
+}
+
+func (c *Config) GetListFormat() ListFormat {
+	return c.listFormat
 }
 
 // singleton instance of Config
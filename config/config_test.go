@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
@@ -10,28 +12,78 @@ import (
 
 func TestNewConfig(t *testing.T) {
 	expectConfig := &Config{
-		gitLabURL:    "https://gitlab.example.com",
-		accessToken:  "example_token",
-		outputDir:    "/tmp/gitlab-repos",
-		groupIDs:     []string{"example_group", "example_group5"},
-		skipGroupIDs: []string{"example_group1", "example_group2"},
-		retryDelay:   3 * time.Second,
-		maxWorkers:   10,
-		maxRetries:   5,
-		useSSH:       true,
-		cloneBare:    false,
+		gitLabURL:           "https://gitlab.example.com",
+		accessToken:         "example_token",
+		outputDir:           "/tmp/gitlab-repos",
+		stateFile:           "state.json",
+		groupIDs:            []string{"example_group", "example_group5"},
+		skipGroupIDs:        []string{"example_group1", "example_group2"},
+		userIDs:             []string{"@me", "alice", "42"},
+		projectIDs:          []string{"42", "group/project"},
+		retryDelay:          3 * time.Second,
+		maxWorkers:          10,
+		maxRetries:          5,
+		useSSH:              true,
+		cloneMode:           CloneModeMirror,
+		syncExisting:        true,
+		pruneMode:           PruneModeQuarantine,
+		quarantineRetention: 7 * 24 * time.Hour,
+		quarantineDir:       "quarantine",
+		resume:              true,
+		journalFile:         "run.journal",
+		stagingDir:          "staging",
+		maxFailurePercent:   10,
+		dryRun:              true,
+		listFormat:          ListFormatNDJSON,
+		membership:          true,
+		minAccessLevel:      30,
+		withShared:          true,
+		sharedPlacement:     SharedPlacementGroup,
+		discoveryStrategy:   DiscoveryStrategySubgroups,
+		apiBackend:          APIBackendGraphQL,
+		adminMode:           true,
+		archived:            ArchivedPolicySkip,
+		forks:               ForksReference,
+		cloneOrder:          CloneOrderLargest,
+		maxRepoSize:         int64(1 << 30),
+		statistics:          true,
 	}
 	expectations := map[string]string{
-		GitlabURLKey:    expectConfig.gitLabURL,
-		GitlabTokenKey:  expectConfig.accessToken,
-		OutputDirKey:    expectConfig.outputDir,
-		GroupIDsKey:     strings.Join(expectConfig.groupIDs, " "),
-		SkipGroupIDsKey: strings.Join(expectConfig.skipGroupIDs, ","),
-		RetryDelayKey:   strconv.Itoa(int(expectConfig.retryDelay.Seconds())),
-		MaxWorkersKey:   strconv.Itoa(expectConfig.maxWorkers),
-		MaxRetriesKey:   strconv.Itoa(expectConfig.maxRetries),
-		UseSSHKey:       strconv.FormatBool(expectConfig.useSSH),
-		CloneBareKey:    strconv.FormatBool(expectConfig.cloneBare),
+		GitlabURLKey:           expectConfig.gitLabURL,
+		GitlabTokenKey:         expectConfig.accessToken,
+		OutputDirKey:           expectConfig.outputDir,
+		StateFileKey:           expectConfig.stateFile,
+		GroupIDsKey:            strings.Join(expectConfig.groupIDs, " "),
+		SkipGroupIDsKey:        strings.Join(expectConfig.skipGroupIDs, ","),
+		UserIDsKey:             strings.Join(expectConfig.userIDs, ", "),
+		ProjectIDsKey:          strings.Join(expectConfig.projectIDs, " "),
+		RetryDelayKey:          strconv.Itoa(int(expectConfig.retryDelay.Seconds())),
+		MaxWorkersKey:          strconv.Itoa(expectConfig.maxWorkers),
+		MaxRetriesKey:          strconv.Itoa(expectConfig.maxRetries),
+		UseSSHKey:              strconv.FormatBool(expectConfig.useSSH),
+		CloneModeKey:           string(expectConfig.cloneMode),
+		SyncExistingKey:        strconv.FormatBool(expectConfig.syncExisting),
+		PruneModeKey:           string(expectConfig.pruneMode),
+		QuarantineRetentionKey: strconv.Itoa(int(expectConfig.quarantineRetention.Hours() / 24)),
+		QuarantineDirKey:       expectConfig.quarantineDir,
+		ResumeKey:              strconv.FormatBool(expectConfig.resume),
+		JournalFileKey:         expectConfig.journalFile,
+		StagingDirKey:          expectConfig.stagingDir,
+		MaxFailurePercentKey:   strconv.Itoa(expectConfig.maxFailurePercent),
+		DryRunKey:              strconv.FormatBool(expectConfig.dryRun),
+		ListFormatKey:          string(expectConfig.listFormat),
+		MembershipKey:          strconv.FormatBool(expectConfig.membership),
+		MinAccessLevelKey:      "developer",
+		WithSharedKey:          strconv.FormatBool(expectConfig.withShared),
+		SharedPlacementKey:     string(expectConfig.sharedPlacement),
+		DiscoveryStrategyKey:   string(expectConfig.discoveryStrategy),
+		APIBackendKey:          string(expectConfig.apiBackend),
+		AdminModeKey:           strconv.FormatBool(expectConfig.adminMode),
+		ArchivedKey:            string(expectConfig.archived),
+		ForksKey:               string(expectConfig.forks),
+		CloneOrderKey:          string(expectConfig.cloneOrder),
+		MaxRepoSizeKey:         strconv.FormatInt(expectConfig.maxRepoSize, 10),
+		StatisticsKey:          strconv.FormatBool(expectConfig.statistics),
 	}
 
 	loader := NewMemoryEnvLoader(expectations)
@@ -48,12 +100,21 @@ func TestNewConfig(t *testing.T) {
 	if config.outputDir != expectConfig.outputDir {
 		t.Errorf("Expected outputDir %s, got %s", expectConfig.outputDir, config.outputDir)
 	}
+	if config.stateFile != expectConfig.stateFile {
+		t.Errorf("Expected stateFile %s, got %s", expectConfig.stateFile, config.stateFile)
+	}
 	if !slices.Equal(config.groupIDs, expectConfig.groupIDs) {
 		t.Errorf("Expected GroupIDs %s, got %s", expectConfig.groupIDs, config.groupIDs)
 	}
 	if !slices.Equal(config.skipGroupIDs, expectConfig.skipGroupIDs) {
 		t.Errorf("Expected skipGroupIDs %s, got %s", expectConfig.skipGroupIDs, config.skipGroupIDs)
 	}
+	if !slices.Equal(config.userIDs, expectConfig.userIDs) {
+		t.Errorf("Expected userIDs %s, got %s", expectConfig.userIDs, config.userIDs)
+	}
+	if !slices.Equal(config.projectIDs, expectConfig.projectIDs) {
+		t.Errorf("Expected projectIDs %s, got %s", expectConfig.projectIDs, config.projectIDs)
+	}
 	if config.retryDelay != expectConfig.retryDelay {
 		t.Errorf("Expected retryDelay %s, got %s", expectConfig.retryDelay, config.retryDelay)
 	}
@@ -66,8 +127,74 @@ func TestNewConfig(t *testing.T) {
 	if config.useSSH != expectConfig.useSSH {
 		t.Errorf("Expected useSSH %t, got %t", expectConfig.useSSH, config.useSSH)
 	}
-	if config.cloneBare != expectConfig.cloneBare {
-		t.Errorf("Expected cloneBare %t, got %t", expectConfig.cloneBare, config.cloneBare)
+	if config.cloneMode != expectConfig.cloneMode {
+		t.Errorf("Expected cloneMode %s, got %s", expectConfig.cloneMode, config.cloneMode)
+	}
+	if config.syncExisting != expectConfig.syncExisting {
+		t.Errorf("Expected syncExisting %t, got %t", expectConfig.syncExisting, config.syncExisting)
+	}
+	if config.pruneMode != expectConfig.pruneMode {
+		t.Errorf("Expected pruneMode %s, got %s", expectConfig.pruneMode, config.pruneMode)
+	}
+	if config.quarantineRetention != expectConfig.quarantineRetention {
+		t.Errorf("Expected quarantineRetention %s, got %s", expectConfig.quarantineRetention, config.quarantineRetention)
+	}
+	if config.quarantineDir != expectConfig.quarantineDir {
+		t.Errorf("Expected quarantineDir %s, got %s", expectConfig.quarantineDir, config.quarantineDir)
+	}
+	if config.resume != expectConfig.resume {
+		t.Errorf("Expected resume %t, got %t", expectConfig.resume, config.resume)
+	}
+	if config.journalFile != expectConfig.journalFile {
+		t.Errorf("Expected journalFile %s, got %s", expectConfig.journalFile, config.journalFile)
+	}
+	if config.stagingDir != expectConfig.stagingDir {
+		t.Errorf("Expected stagingDir %s, got %s", expectConfig.stagingDir, config.stagingDir)
+	}
+	if config.maxFailurePercent != expectConfig.maxFailurePercent {
+		t.Errorf("Expected maxFailurePercent %d, got %d", expectConfig.maxFailurePercent, config.maxFailurePercent)
+	}
+	if config.dryRun != expectConfig.dryRun {
+		t.Errorf("Expected dryRun %t, got %t", expectConfig.dryRun, config.dryRun)
+	}
+	if config.listFormat != expectConfig.listFormat {
+		t.Errorf("Expected listFormat %s, got %s", expectConfig.listFormat, config.listFormat)
+	}
+	if config.membership != expectConfig.membership {
+		t.Errorf("Expected membership %t, got %t", expectConfig.membership, config.membership)
+	}
+	if config.minAccessLevel != expectConfig.minAccessLevel {
+		t.Errorf("Expected minAccessLevel %d, got %d", expectConfig.minAccessLevel, config.minAccessLevel)
+	}
+	if config.withShared != expectConfig.withShared {
+		t.Errorf("Expected withShared %t, got %t", expectConfig.withShared, config.withShared)
+	}
+	if config.sharedPlacement != expectConfig.sharedPlacement {
+		t.Errorf("Expected sharedPlacement %s, got %s", expectConfig.sharedPlacement, config.sharedPlacement)
+	}
+	if config.discoveryStrategy != expectConfig.discoveryStrategy {
+		t.Errorf("Expected discoveryStrategy %s, got %s", expectConfig.discoveryStrategy, config.discoveryStrategy)
+	}
+	if config.apiBackend != expectConfig.apiBackend {
+		t.Errorf("Expected apiBackend %s, got %s", expectConfig.apiBackend, config.apiBackend)
+	}
+	if config.adminMode != expectConfig.adminMode {
+		t.Errorf("Expected adminMode %t, got %t", expectConfig.adminMode, config.adminMode)
+	}
+	if config.archived != expectConfig.archived {
+		t.Errorf("Expected archived %s, got %s", expectConfig.archived, config.archived)
+	}
+	if config.forks != expectConfig.forks {
+		t.Errorf("Expected forks %s, got %s", expectConfig.forks, config.forks)
+	}
+	if config.cloneOrder != expectConfig.cloneOrder {
+		t.Errorf("Expected cloneOrder %s, got %s", expectConfig.cloneOrder, config.cloneOrder)
+	}
+	if config.maxRepoSize != expectConfig.maxRepoSize {
+		t.Errorf("Expected maxRepoSize %d, got %d", expectConfig.maxRepoSize, config.maxRepoSize)
+	}
+	if config.statistics != expectConfig.statistics {
+		t.Errorf("Expected statistics %t, got %t", expectConfig.statistics, config.statistics)
 	}
 
 	// Verify getters
@@ -80,12 +207,24 @@ func TestNewConfig(t *testing.T) {
 	if config.GetOutputDir() != config.outputDir {
 		t.Errorf("Expected outputDir %s, got %s", config.outputDir, config.GetOutputDir())
 	}
+	if config.GetJournalFile() != "/tmp/gitlab-repos/run.journal" {
+		t.Errorf("Expected journalFile %s, got %s", "/tmp/gitlab-repos/run.journal", config.GetJournalFile())
+	}
+	if config.GetStateFile() != "/tmp/gitlab-repos/state.json" {
+		t.Errorf("Expected stateFile %s, got %s", "/tmp/gitlab-repos/state.json", config.GetStateFile())
+	}
 	if !slices.Equal(config.GetGroupIDs(), config.groupIDs) {
 		t.Errorf("Expected GroupIDs %s, got %s", config.groupIDs, config.GetGroupIDs())
 	}
 	if !slices.Equal(config.GetSkipGroupIDs(), config.skipGroupIDs) {
 		t.Errorf("Expected skipGroupIDs %s, got %s", config.skipGroupIDs, config.GetSkipGroupIDs())
 	}
+	if !slices.Equal(config.GetUserIDs(), config.userIDs) {
+		t.Errorf("Expected userIDs %s, got %s", config.userIDs, config.GetUserIDs())
+	}
+	if !slices.Equal(config.GetProjectIDs(), config.projectIDs) {
+		t.Errorf("Expected projectIDs %s, got %s", config.projectIDs, config.GetProjectIDs())
+	}
 	if config.GetRetryDelay() != config.retryDelay {
 		t.Errorf("Expected retryDelay %s, got %s", config.retryDelay, config.GetRetryDelay())
 	}
@@ -98,8 +237,62 @@ func TestNewConfig(t *testing.T) {
 	if config.GetUseSSH() != config.useSSH {
 		t.Errorf("Expected useSSH %t, got %t", config.useSSH, config.GetUseSSH())
 	}
-	if config.GetCloneBare() != config.cloneBare {
-		t.Errorf("Expected cloneBare %t, got %t", config.cloneBare, config.GetCloneBare())
+	if config.GetCloneMode() != config.cloneMode {
+		t.Errorf("Expected cloneMode %s, got %s", config.cloneMode, config.GetCloneMode())
+	}
+	if config.GetSyncExisting() != config.syncExisting {
+		t.Errorf("Expected syncExisting %t, got %t", config.syncExisting, config.GetSyncExisting())
+	}
+	if config.GetPruneMode() != config.pruneMode {
+		t.Errorf("Expected pruneMode %s, got %s", config.pruneMode, config.GetPruneMode())
+	}
+	if config.GetQuarantineRetention() != config.quarantineRetention {
+		t.Errorf("Expected quarantineRetention %s, got %s", config.quarantineRetention, config.GetQuarantineRetention())
+	}
+	if config.GetResume() != config.resume {
+		t.Errorf("Expected resume %t, got %t", config.resume, config.GetResume())
+	}
+	if config.GetMaxFailurePercent() != config.maxFailurePercent {
+		t.Errorf("Expected maxFailurePercent %d, got %d", config.maxFailurePercent, config.GetMaxFailurePercent())
+	}
+	if config.GetDryRun() != config.dryRun {
+		t.Errorf("Expected dryRun %t, got %t", config.dryRun, config.GetDryRun())
+	}
+	if config.GetListFormat() != config.listFormat {
+		t.Errorf("Expected listFormat %s, got %s", config.listFormat, config.GetListFormat())
+	}
+	if config.GetMembership() != config.membership {
+		t.Errorf("Expected membership %t, got %t", config.membership, config.GetMembership())
+	}
+	if config.GetMinAccessLevel() != config.minAccessLevel {
+		t.Errorf("Expected minAccessLevel %d, got %d", config.minAccessLevel, config.GetMinAccessLevel())
+	}
+	if config.GetWithShared() != config.withShared {
+		t.Errorf("Expected withShared %t, got %t", config.withShared, config.GetWithShared())
+	}
+	if config.GetSharedPlacement() != config.sharedPlacement {
+		t.Errorf("Expected sharedPlacement %s, got %s", config.sharedPlacement, config.GetSharedPlacement())
+	}
+	if config.GetDiscoveryStrategy() != config.discoveryStrategy {
+		t.Errorf("Expected discoveryStrategy %s, got %s", config.discoveryStrategy, config.GetDiscoveryStrategy())
+	}
+	if config.GetAPIBackend() != config.apiBackend {
+		t.Errorf("Expected apiBackend %s, got %s", config.apiBackend, config.GetAPIBackend())
+	}
+	if config.GetAdminMode() != config.adminMode {
+		t.Errorf("Expected adminMode %t, got %t", config.adminMode, config.GetAdminMode())
+	}
+	if config.GetArchived() != config.archived {
+		t.Errorf("Expected archived %s, got %s", config.archived, config.GetArchived())
+	}
+	if config.GetForks() != config.forks {
+		t.Errorf("Expected forks %s, got %s", config.forks, config.GetForks())
+	}
+	if config.GetCloneOrder() != config.cloneOrder {
+		t.Errorf("Expected cloneOrder %s, got %s", config.cloneOrder, config.GetCloneOrder())
+	}
+	if config.GetMaxRepoSize() != config.maxRepoSize {
+		t.Errorf("Expected maxRepoSize %d, got %d", config.maxRepoSize, config.GetMaxRepoSize())
 	}
 
 	beforeDefaultLoader := DefaultEnvLoader
@@ -117,6 +310,9 @@ func TestNewConfig(t *testing.T) {
 	if config.outputDir != expectConfig.outputDir {
 		t.Errorf("Expected outputDir %s, got %s", expectConfig.outputDir, config.outputDir)
 	}
+	if config.stateFile != expectConfig.stateFile {
+		t.Errorf("Expected stateFile %s, got %s", expectConfig.stateFile, config.stateFile)
+	}
 	if !slices.Equal(config.groupIDs, expectConfig.groupIDs) {
 		t.Errorf("Expected GroupIDs %s, got %s", expectConfig.groupIDs, config.groupIDs)
 	}
@@ -135,8 +331,11 @@ func TestNewConfig(t *testing.T) {
 	if config.useSSH != expectConfig.useSSH {
 		t.Errorf("Expected useSSH %t, got %t", expectConfig.useSSH, config.useSSH)
 	}
-	if config.cloneBare != expectConfig.cloneBare {
-		t.Errorf("Expected cloneBare %t, got %t", expectConfig.cloneBare, config.cloneBare)
+	if config.cloneMode != expectConfig.cloneMode {
+		t.Errorf("Expected cloneMode %s, got %s", expectConfig.cloneMode, config.cloneMode)
+	}
+	if config.syncExisting != expectConfig.syncExisting {
+		t.Errorf("Expected syncExisting %t, got %t", expectConfig.syncExisting, config.syncExisting)
 	}
 }
 
@@ -179,3 +378,432 @@ func TestExtractGroupIDs(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractCloneMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		envs     map[string]string
+		expected CloneMode
+	}{
+		{"default", map[string]string{}, CloneModeBare},
+		{"working", map[string]string{CloneModeKey: "working"}, CloneModeWorking},
+		{"mirror in upper case", map[string]string{CloneModeKey: " MIRROR "}, CloneModeMirror},
+		{"unknown mode", map[string]string{CloneModeKey: "shallow"}, DefaultCloneMode},
+		{"legacy bare", map[string]string{CloneBareKey: "true"}, CloneModeBare},
+		{"legacy not bare", map[string]string{CloneBareKey: "false"}, CloneModeWorking},
+		{"empty mode uses legacy", map[string]string{CloneModeKey: "", CloneBareKey: "false"}, CloneModeWorking},
+		{"mode wins over legacy", map[string]string{CloneModeKey: "mirror", CloneBareKey: "false"}, CloneModeMirror},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := extractCloneMode(NewMemoryEnvLoader(test.envs))
+			if result != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestGetStateFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		outputDir string
+		stateFile string
+		expected  string
+	}{
+		{"default", "", "", DefaultStateFile},
+		{"relative to output dir", "repos", "state.json", "repos/state.json"},
+		{"absolute path", "repos", "/var/lib/state.json", "/var/lib/state.json"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := NewConfig(NewMemoryEnvLoader(map[string]string{
+				OutputDirKey: test.outputDir,
+				StateFileKey: test.stateFile,
+			}))
+			if config.GetStateFile() != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, config.GetStateFile())
+			}
+		})
+	}
+}
+
+func TestExtractPruneMode(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected PruneMode
+	}{
+		{"", PruneModeOff},
+		{"off", PruneModeOff},
+		{"dry-run", PruneModeDryRun},
+		{"Quarantine", PruneModeQuarantine},
+		{"delete", PruneModeDelete},
+		{"everything", PruneModeOff},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result := extractPruneMode(NewMemoryEnvLoader(map[string]string{PruneModeKey: test.value}))
+			if result != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestExtractListFormat(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected ListFormat
+	}{
+		{"", ListFormatTable},
+		{"table", ListFormatTable},
+		{"JSON", ListFormatJSON},
+		{"ndjson", ListFormatNDJSON},
+		{"yaml", ListFormatTable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result := extractListFormat(NewMemoryEnvLoader(map[string]string{ListFormatKey: test.value}))
+			if result != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestExtractSharedPlacement(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected SharedPlacement
+	}{
+		{"", SharedPlacementNamespace},
+		{"namespace", SharedPlacementNamespace},
+		{"Group", SharedPlacementGroup},
+		{"root", SharedPlacementNamespace},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result := extractSharedPlacement(NewMemoryEnvLoader(map[string]string{SharedPlacementKey: test.value}))
+			if result != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestExtractDiscoveryStrategy(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected DiscoveryStrategy
+	}{
+		{"", DiscoveryStrategyWalk},
+		{"walk", DiscoveryStrategyWalk},
+		{"Subgroups", DiscoveryStrategySubgroups},
+		{"bfs", DiscoveryStrategyWalk},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result := extractDiscoveryStrategy(NewMemoryEnvLoader(map[string]string{DiscoveryStrategyKey: test.value}))
+			if result != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestExtractAPIBackend(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected APIBackend
+	}{
+		{"", APIBackendREST},
+		{"rest", APIBackendREST},
+		{"GraphQL", APIBackendGraphQL},
+		{"soap", APIBackendREST},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result := extractAPIBackend(NewMemoryEnvLoader(map[string]string{APIBackendKey: test.value}))
+			if result != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestGetQuarantineDir(t *testing.T) {
+	config := NewConfig(NewMemoryEnvLoader(map[string]string{
+		OutputDirKey: "repos",
+	}))
+	if config.GetQuarantineDir() != "repos/"+DefaultQuarantineDir {
+		t.Errorf("Expected %s, got %s", "repos/"+DefaultQuarantineDir, config.GetQuarantineDir())
+	}
+
+	config = NewConfig(NewMemoryEnvLoader(map[string]string{
+		OutputDirKey:     "repos",
+		QuarantineDirKey: "/backup/quarantine",
+	}))
+	if config.GetQuarantineDir() != "/backup/quarantine" {
+		t.Errorf("Expected %s, got %s", "/backup/quarantine", config.GetQuarantineDir())
+	}
+}
+
+func TestExtractArchivedPolicy(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected ArchivedPolicy
+	}{
+		{"", ArchivedPolicyInclude},
+		{"skip", ArchivedPolicySkip},
+		{" ONLY ", ArchivedPolicyOnly},
+		{"never", ArchivedPolicyInclude},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result := extractArchivedPolicy(NewMemoryEnvLoader(map[string]string{ArchivedKey: test.value}))
+			if result != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestExtractForksMode(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected ForksMode
+	}{
+		{"", ForksFull},
+		{"skip", ForksSkip},
+		{"Reference", ForksReference},
+		{"shallow", ForksFull},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result := extractForksMode(NewMemoryEnvLoader(map[string]string{ForksKey: test.value}))
+			if result != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestExtractCloneOrder(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected CloneOrder
+	}{
+		{"", CloneOrderDiscovery},
+		{"smallest", CloneOrderSmallest},
+		{"LARGEST", CloneOrderLargest},
+		{"random", CloneOrderDiscovery},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result := extractCloneOrder(NewMemoryEnvLoader(map[string]string{CloneOrderKey: test.value}))
+			if result != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestExtractVisibilities(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []Visibility
+	}{
+		{"", nil},
+		{"private", []Visibility{VisibilityPrivate}},
+		{"Private, INTERNAL private", []Visibility{VisibilityPrivate, VisibilityInternal}},
+		{"public,secret", []Visibility{VisibilityPublic}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result := extractVisibilities(test.value)
+			if !slices.Equal(result, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestExtractTopics(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []string
+	}{
+		{"", nil},
+		{"pci", []string{"pci"}},
+		{" pci , production,, pci", []string{"pci", "production"}},
+		{"machine learning,ml", []string{"machine learning", "ml"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result := extractTopics(test.value)
+			if !slices.Equal(result, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestGetStatistics(t *testing.T) {
+	tests := []struct {
+		name     string
+		envs     map[string]string
+		expected bool
+	}{
+		{"default", map[string]string{}, false},
+		{"enabled", map[string]string{StatisticsKey: "true"}, true},
+		{"size limit", map[string]string{MaxRepoSizeKey: "1GB"}, true},
+		{"clone order", map[string]string{CloneOrderKey: "largest"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := NewConfig(NewMemoryEnvLoader(test.envs)).GetStatistics(); result != test.expected {
+				t.Errorf("Expected %t, got %t", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestGetArchivedOutputDir(t *testing.T) {
+	config := NewConfig(NewMemoryEnvLoader(map[string]string{
+		OutputDirKey: "repos",
+	}))
+	if config.GetArchivedOutputDir() != "" {
+		t.Errorf("Expected an empty directory, got %s", config.GetArchivedOutputDir())
+	}
+
+	config = NewConfig(NewMemoryEnvLoader(map[string]string{
+		OutputDirKey:         "repos",
+		ArchivedOutputDirKey: "archived",
+	}))
+	if config.GetArchivedOutputDir() != "repos/archived" {
+		t.Errorf("Expected %s, got %s", "repos/archived", config.GetArchivedOutputDir())
+	}
+
+	config = NewConfig(NewMemoryEnvLoader(map[string]string{
+		OutputDirKey:         "repos",
+		ArchivedOutputDirKey: "/cold/repos",
+	}))
+	if config.GetArchivedOutputDir() != "/cold/repos" {
+		t.Errorf("Expected %s, got %s", "/cold/repos", config.GetArchivedOutputDir())
+	}
+}
+
+func TestGetStagingDir(t *testing.T) {
+	config := NewConfig(NewMemoryEnvLoader(map[string]string{
+		OutputDirKey: "repos",
+	}))
+	if config.GetStagingDir() != "repos/"+DefaultStagingDir {
+		t.Errorf("Expected %s, got %s", "repos/"+DefaultStagingDir, config.GetStagingDir())
+	}
+
+	config = NewConfig(NewMemoryEnvLoader(map[string]string{
+		OutputDirKey:  "repos",
+		StagingDirKey: "/tmp/staging",
+	}))
+	if config.GetStagingDir() != "/tmp/staging" {
+		t.Errorf("Expected %s, got %s", "/tmp/staging", config.GetStagingDir())
+	}
+}
+
+func TestGetArchivedStagingDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		envs     map[string]string
+		expected []string
+	}{
+		{
+			name:     "no archived output directory",
+			envs:     map[string]string{OutputDirKey: "repos"},
+			expected: []string{"repos/" + DefaultStagingDir},
+		},
+		{
+			name:     "archived output directory",
+			envs:     map[string]string{OutputDirKey: "repos", ArchivedOutputDirKey: "/cold/repos"},
+			expected: []string{"repos/" + DefaultStagingDir, "/cold/repos/" + DefaultStagingDir},
+		},
+		{
+			name: "absolute staging directory",
+			envs: map[string]string{
+				OutputDirKey:         "repos",
+				ArchivedOutputDirKey: "/cold/repos",
+				StagingDirKey:        "/tmp/staging",
+			},
+			expected: []string{"/tmp/staging", "/cold/repos/" + DefaultStagingDir},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := NewConfig(NewMemoryEnvLoader(test.envs)).GetStagingDirs()
+			if !slices.Equal(result, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestExtractProjectIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.txt")
+	content := "# backup list\n42\n\n  group/project  \nother/project\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	projectIDs := extractProjectIDs(NewMemoryEnvLoader(map[string]string{
+		ProjectIDsKey:     "42, single/project",
+		ProjectIDsFileKey: path,
+	}))
+
+	expected := []string{"42", "single/project", "group/project", "other/project"}
+	if !slices.Equal(projectIDs, expected) {
+		t.Errorf("Expected %s, got %s", expected, projectIDs)
+	}
+
+	projectIDs = extractProjectIDs(NewMemoryEnvLoader(map[string]string{
+		ProjectIDsKey:     "42",
+		ProjectIDsFileKey: filepath.Join(t.TempDir(), "missing.txt"),
+	}))
+	if !slices.Equal(projectIDs, []string{"42"}) {
+		t.Errorf("Expected %s, got %s", []string{"42"}, projectIDs)
+	}
+}
+
+func TestExtractAccessLevel(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+	}{
+		{"", 0},
+		{"guest", 10},
+		{"Developer", 30},
+		{"maintainer", 40},
+		{"40", 40},
+		{"admin", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			if result := extractAccessLevel(test.value); result != test.expected {
+				t.Errorf("Expected %d, got %d", test.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// instanceKeyPrefix starts the variables of an instance, e.g. RE_INSTANCE_INTERNAL_GITLAB_URL for RE_GITLAB_URL.
+const instanceKeyPrefix = "RE_INSTANCE_"
+
+// instanceOnlyKeys are the variables which identify what to back up from an instance,
+// they are never inherited from the global configuration, so a token or a group is never sent to another instance.
+var instanceOnlyKeys = []string{
+	InstancesKey,
+	GitlabURLKey,
+	GitlabTokenKey,
+	GroupIDsKey,
+	SkipGroupIDsKey,
+	UserIDsKey,
+	ProjectIDsKey,
+	ProjectIDsFileKey,
+	MembershipKey,
+	MinAccessLevelKey,
+	AdminModeKey,
+}
+
+// ErrorInstance is an error type that indicates a problem of the configuration of an instance.
+type ErrorInstance struct {
+	name          string
+	originalError error
+}
+
+func (e *ErrorInstance) Error() string {
+	return fmt.Sprintf("instance %s: %v", e.name, e.originalError)
+}
+
+func (e *ErrorInstance) Unwrap() error {
+	return e.originalError
+}
+
+// instancePathKeys are the files of a run, an absolute global path is placed into a directory named after
+// the instance, as the state and the journal are keyed by project IDs which repeat across instances.
+var instancePathKeys = []string{
+	StateFileKey,
+	JournalFileKey,
+}
+
+// instanceEnvLoader reads the variables of an instance, e.g. RE_INSTANCE_INTERNAL_GITLAB_URL for RE_GITLAB_URL,
+// and falls back to the global ones except instanceOnlyKeys.
+// The output directory is the OutputSubDirSuffix subdirectory of the global one, the instance name by default.
+type instanceEnvLoader struct {
+	base EnvLoader
+	name string
+}
+
+// Load does nothing, the global loader is already loaded.
+func (l *instanceEnvLoader) Load() {}
+
+func (l *instanceEnvLoader) Get(key string, defaultValue ...string) string {
+	if key == OutputDirKey {
+		subDir := l.base.Get(l.instanceKey(OutputSubDirSuffix), l.name)
+		return filepath.Join(l.base.Get(OutputDirKey, DefaultOutputDir), subDir)
+	}
+
+	if value := l.base.Get(l.instanceKey(strings.TrimPrefix(key, "RE_"))); value != "" {
+		return value
+	}
+
+	if slices.Contains(instanceOnlyKeys, key) {
+		if len(defaultValue) > 0 {
+			return defaultValue[0]
+		}
+		return ""
+	}
+
+	value := l.base.Get(key, defaultValue...)
+	if slices.Contains(instancePathKeys, key) && filepath.IsAbs(value) {
+		return filepath.Join(filepath.Dir(value), l.name, filepath.Base(value))
+	}
+
+	return value
+}
+
+func (l *instanceEnvLoader) GetInt(key string, defaultValue int) int {
+	return getInt(l.Get(key), defaultValue)
+}
+
+// instanceKey returns the name of the variable of the instance with the suffix.
+func (l *instanceEnvLoader) instanceKey(suffix string) string {
+	return instanceKeyPrefix + instanceKeyName(l.name) + "_" + suffix
+}
+
+// instanceKeyName returns the instance name as used in variable names: upper-cased, other characters than
+// letters and digits are replaced by underscores.
+func instanceKeyName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+}
+
+// extractInstances returns the configurations of the instances of InstancesKey, nil when it isn't set.
+func extractInstances(loader EnvLoader) []*Config {
+	names := extractGroupIDs(loader.Get(InstancesKey))
+	if len(names) == 0 {
+		return nil
+	}
+
+	instances := make([]*Config, 0, len(names))
+	for _, name := range names {
+		instance := NewConfig(&instanceEnvLoader{loader, name})
+		instance.instanceName = name
+		instances = append(instances, instance)
+	}
+
+	return instances
+}
+
+// GetInstances returns the configurations of all instances processed in a run,
+// the configuration itself when RE_INSTANCES isn't set.
+func (c *Config) GetInstances() []*Config {
+	if len(c.instances) == 0 {
+		return []*Config{c}
+	}
+
+	return c.instances
+}
+
+// GetInstanceName returns the name of the instance in RE_INSTANCES, empty without RE_INSTANCES.
+func (c *Config) GetInstanceName() string {
+	return c.instanceName
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func getInstancesEnvs() map[string]string {
+	return map[string]string{
+		InstancesKey:                          "gitlab.com, internal",
+		OutputDirKey:                          "backup",
+		GitlabTokenKey:                        "global-token",
+		GroupIDsKey:                           "global-group",
+		MaxWorkersKey:                         "3",
+		"RE_INSTANCE_GITLAB_COM_GITLAB_TOKEN": "com-token",
+		"RE_INSTANCE_GITLAB_COM_GROUP_IDS":    "org, org/team",
+		"RE_INSTANCE_INTERNAL_GITLAB_URL":     "https://git.internal",
+		"RE_INSTANCE_INTERNAL_GITLAB_TOKEN":   "internal-token",
+		"RE_INSTANCE_INTERNAL_USE_SSH":        "true",
+		"RE_INSTANCE_INTERNAL_OUTPUT_SUBDIR":  "corp",
+	}
+}
+
+func TestGetInstances(t *testing.T) {
+	config := NewConfig(NewMemoryEnvLoader(getInstancesEnvs()))
+
+	instances := config.GetInstances()
+	if len(instances) != 2 {
+		t.Fatalf("Expected 2 instances, got %d", len(instances))
+	}
+
+	com, internal := instances[0], instances[1]
+
+	if com.GetInstanceName() != "gitlab.com" || internal.GetInstanceName() != "internal" {
+		t.Errorf("Unexpected instance names %s and %s", com.GetInstanceName(), internal.GetInstanceName())
+	}
+
+	if com.GetGitLabURL() != DefaultGitlabURL || com.GetAccessToken() != "com-token" {
+		t.Errorf("Unexpected connection of gitlab.com: %s, %s", com.GetGitLabURL(), com.GetAccessToken())
+	}
+	if !slices.Equal(com.GetGroupIDs(), []string{"org", "org/team"}) {
+		t.Errorf("Unexpected group IDs of gitlab.com: %v", com.GetGroupIDs())
+	}
+	if com.GetOutputDir() != "backup/gitlab.com" || com.GetUseSSH() {
+		t.Errorf("Unexpected output of gitlab.com: %s, SSH %t", com.GetOutputDir(), com.GetUseSSH())
+	}
+
+	if internal.GetGitLabURL() != "https://git.internal" || internal.GetAccessToken() != "internal-token" {
+		t.Errorf("Unexpected connection of internal: %s, %s", internal.GetGitLabURL(), internal.GetAccessToken())
+	}
+	if len(internal.GetGroupIDs()) != 0 {
+		t.Errorf("Expected global group IDs not to be inherited, got %v", internal.GetGroupIDs())
+	}
+	if internal.GetOutputDir() != "backup/corp" || !internal.GetUseSSH() {
+		t.Errorf("Unexpected output of internal: %s, SSH %t", internal.GetOutputDir(), internal.GetUseSSH())
+	}
+	if internal.GetStateFile() != "backup/corp/"+DefaultStateFile {
+		t.Errorf("Expected a state file per instance, got %s", internal.GetStateFile())
+	}
+
+	for _, instance := range instances {
+		if instance.GetMaxWorkers() != 3 {
+			t.Errorf("Expected inherited max workers 3, got %d", instance.GetMaxWorkers())
+		}
+	}
+}
+
+func TestGetInstances_Single(t *testing.T) {
+	config := NewConfig(NewMemoryEnvLoader(map[string]string{GitlabTokenKey: "token"}))
+
+	instances := config.GetInstances()
+	if len(instances) != 1 || instances[0] != config || config.GetInstanceName() != "" {
+		t.Errorf("Expected the configuration itself, got %v", instances)
+	}
+}
+
+func TestValidate_Instances(t *testing.T) {
+	envs := getInstancesEnvs()
+	delete(envs, "RE_INSTANCE_INTERNAL_GITLAB_TOKEN")
+	envs["RE_INSTANCE_GITLAB_COM_CLONE_MODE"] = "shallow"
+
+	err := NewConfig(NewMemoryEnvLoader(envs)).Validate()
+	if !errors.Is(err, ErrorMissingAccessToken) {
+		t.Errorf("Expected missing access token of an instance, got %v", err)
+	}
+
+	expected := `instance gitlab.com: invalid value of RE_CLONE_MODE: "shallow"` + "\n" +
+		"instance internal: " + ErrorMissingAccessToken.Error()
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error:\n%s\ngot:\n%v", expected, err)
+	}
+
+	delete(envs, "RE_INSTANCE_GITLAB_COM_CLONE_MODE")
+	envs["RE_INSTANCE_INTERNAL_GITLAB_TOKEN"] = "internal-token"
+	if err := NewConfig(NewMemoryEnvLoader(envs)).Validate(); err != nil {
+		t.Errorf("Expected valid instances, got %v", err)
+	}
+}
+
+func TestGetInstances_AbsoluteRunFiles(t *testing.T) {
+	envs := getInstancesEnvs()
+	envs[StateFileKey] = "/var/lib/extractor/state.json"
+	envs[JournalFileKey] = "/var/lib/extractor/journal.ndjson"
+
+	config := NewConfig(NewMemoryEnvLoader(envs))
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected valid instances, got %v", err)
+	}
+
+	com, internal := config.GetInstances()[0], config.GetInstances()[1]
+	if com.GetStateFile() != "/var/lib/extractor/gitlab.com/state.json" ||
+		internal.GetStateFile() != "/var/lib/extractor/internal/state.json" {
+		t.Errorf("Expected a state file per instance, got %s and %s", com.GetStateFile(), internal.GetStateFile())
+	}
+	if com.GetJournalFile() != "/var/lib/extractor/gitlab.com/journal.ndjson" ||
+		internal.GetJournalFile() != "/var/lib/extractor/internal/journal.ndjson" {
+		t.Errorf("Expected a journal per instance, got %s and %s", com.GetJournalFile(), internal.GetJournalFile())
+	}
+
+	envs["RE_INSTANCE_INTERNAL_STATE_FILE"] = "/var/lib/extractor/gitlab.com/state.json"
+	err := NewConfig(NewMemoryEnvLoader(envs)).Validate()
+	expected := `instance internal: invalid value of RE_STATE_FILE: "/var/lib/extractor/gitlab.com/state.json"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error:\n%s\ngot:\n%v", expected, err)
+	}
+}
+
+func TestInstanceKeyName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"internal", "INTERNAL"},
+		{"gitlab.com", "GITLAB_COM"},
+		{"eu-west", "EU_WEST"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := instanceKeyName(test.name); result != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, result)
+			}
+		})
+	}
+}
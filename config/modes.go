@@ -0,0 +1,284 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// CloneMode defines how projects are cloned.
+type CloneMode string
+
+const (
+	// CloneModeWorking clones projects with a working directory.
+	CloneModeWorking CloneMode = "working"
+	// CloneModeBare clones only branches and tags without a working directory.
+	CloneModeBare CloneMode = "bare"
+	// CloneModeMirror clones all refs, including merge-request and other GitLab-internal refs.
+	CloneModeMirror CloneMode = "mirror"
+)
+
+// PruneMode defines what to do with local repositories which no longer exist on GitLab.
+type PruneMode string
+
+const (
+	// PruneModeOff keeps orphaned repositories.
+	PruneModeOff PruneMode = "off"
+	// PruneModeDryRun only lists orphaned repositories.
+	PruneModeDryRun PruneMode = "dry-run"
+	// PruneModeQuarantine moves orphaned repositories to the quarantine directory.
+	PruneModeQuarantine PruneMode = "quarantine"
+	// PruneModeDelete deletes orphaned repositories.
+	PruneModeDelete PruneMode = "delete"
+)
+
+// ListFormat defines how the project list of a dry run is printed.
+type ListFormat string
+
+const (
+	// ListFormatTable prints a human-readable group tree and project table.
+	ListFormatTable ListFormat = "table"
+	// ListFormatJSON prints a single JSON document.
+	ListFormatJSON ListFormat = "json"
+	// ListFormatNDJSON prints a JSON object per line.
+	ListFormatNDJSON ListFormat = "ndjson"
+)
+
+// SharedPlacement defines where projects shared with a group are cloned.
+type SharedPlacement string
+
+const (
+	// SharedPlacementNamespace clones shared projects under their own namespace.
+	SharedPlacementNamespace SharedPlacement = "namespace"
+	// SharedPlacementGroup clones shared projects under the directory of the group they are shared with.
+	SharedPlacementGroup SharedPlacement = "group"
+)
+
+// DiscoveryStrategy defines how projects of groups are listed.
+type DiscoveryStrategy string
+
+const (
+	// DiscoveryStrategyWalk lists the projects of every group separately.
+	DiscoveryStrategyWalk DiscoveryStrategy = "walk"
+	// DiscoveryStrategySubgroups lists the projects of a whole group tree in one paginated request
+	// to its root group, falling back to the walk when the request fails.
+	DiscoveryStrategySubgroups DiscoveryStrategy = "subgroups"
+)
+
+// APIBackend defines which GitLab API is used to list subgroups and projects of groups.
+type APIBackend string
+
+const (
+	// APIBackendREST uses the REST API.
+	APIBackendREST APIBackend = "rest"
+	// APIBackendGraphQL uses the GraphQL API with cursor pagination, the REST API is used for everything else.
+	APIBackendGraphQL APIBackend = "graphql"
+)
+
+// ArchivedPolicy defines what to do with archived projects.
+type ArchivedPolicy string
+
+const (
+	// ArchivedPolicyInclude clones archived projects with the active ones.
+	ArchivedPolicyInclude ArchivedPolicy = "include"
+	// ArchivedPolicySkip clones only active projects.
+	ArchivedPolicySkip ArchivedPolicy = "skip"
+	// ArchivedPolicyOnly clones only archived projects.
+	ArchivedPolicyOnly ArchivedPolicy = "only"
+)
+
+// ForksMode defines how forks are cloned.
+type ForksMode string
+
+const (
+	// ForksFull clones forks like any other project.
+	ForksFull ForksMode = "full"
+	// ForksSkip doesn't clone forks.
+	ForksSkip ForksMode = "skip"
+	// ForksReference clones forks after their upstream projects of the same run and borrows the objects
+	// of the upstream clones through alternates, so only the missing objects are fetched and stored.
+	ForksReference ForksMode = "reference"
+)
+
+// Visibility is the visibility level of a project.
+type Visibility string
+
+const (
+	// VisibilityPrivate projects are visible to their members only.
+	VisibilityPrivate Visibility = "private"
+	// VisibilityInternal projects are visible to every signed-in user.
+	VisibilityInternal Visibility = "internal"
+	// VisibilityPublic projects are visible to everyone.
+	VisibilityPublic Visibility = "public"
+)
+
+// CloneOrder defines the order in which discovered projects are cloned.
+type CloneOrder string
+
+const (
+	// CloneOrderDiscovery clones projects as they are discovered.
+	CloneOrderDiscovery CloneOrder = "discovery"
+	// CloneOrderSmallest clones the smallest repositories first.
+	CloneOrderSmallest CloneOrder = "smallest"
+	// CloneOrderLargest clones the largest repositories first, so they don't delay the end of the run.
+	CloneOrderLargest CloneOrder = "largest"
+)
+
+// accessLevels maps role names to GitLab access levels.
+var accessLevels = map[string]int{
+	"guest":      10,
+	"planner":    15,
+	"reporter":   20,
+	"developer":  30,
+	"maintainer": 40,
+	"owner":      50,
+}
+
+// extractAccessLevel returns the access level of a role name or a number, 0 means no minimum.
+func extractAccessLevel(value string) int {
+	value = normalizeMode(value)
+	if level, ok := accessLevels[value]; ok {
+		return level
+	}
+
+	return getInt(value, 0)
+}
+
+func extractCloneMode(loader EnvLoader) CloneMode {
+	mode := CloneMode(normalizeMode(loader.Get(CloneModeKey)))
+
+	switch mode {
+	case CloneModeWorking, CloneModeBare, CloneModeMirror:
+		return mode
+	case "":
+		if loader.Get(CloneBareKey, DefaultCloneBare) == "true" {
+			return CloneModeBare
+		}
+
+		return CloneModeWorking
+	default:
+		return DefaultCloneMode
+	}
+}
+
+func extractPruneMode(loader EnvLoader) PruneMode {
+	mode := PruneMode(normalizeMode(loader.Get(PruneModeKey, string(DefaultPruneMode))))
+
+	switch mode {
+	case PruneModeOff, PruneModeDryRun, PruneModeQuarantine, PruneModeDelete:
+		return mode
+	default:
+		return DefaultPruneMode
+	}
+}
+
+func extractSharedPlacement(loader EnvLoader) SharedPlacement {
+	placement := SharedPlacement(normalizeMode(loader.Get(SharedPlacementKey, string(DefaultSharedPlacement))))
+
+	switch placement {
+	case SharedPlacementNamespace, SharedPlacementGroup:
+		return placement
+	default:
+		return DefaultSharedPlacement
+	}
+}
+
+func extractDiscoveryStrategy(loader EnvLoader) DiscoveryStrategy {
+	strategy := DiscoveryStrategy(normalizeMode(loader.Get(DiscoveryStrategyKey, string(DefaultDiscoveryStrategy))))
+
+	switch strategy {
+	case DiscoveryStrategyWalk, DiscoveryStrategySubgroups:
+		return strategy
+	default:
+		return DefaultDiscoveryStrategy
+	}
+}
+
+func extractAPIBackend(loader EnvLoader) APIBackend {
+	backend := APIBackend(normalizeMode(loader.Get(APIBackendKey, string(DefaultAPIBackend))))
+
+	switch backend {
+	case APIBackendREST, APIBackendGraphQL:
+		return backend
+	default:
+		return DefaultAPIBackend
+	}
+}
+
+func extractArchivedPolicy(loader EnvLoader) ArchivedPolicy {
+	policy := ArchivedPolicy(normalizeMode(loader.Get(ArchivedKey, string(DefaultArchived))))
+
+	switch policy {
+	case ArchivedPolicyInclude, ArchivedPolicySkip, ArchivedPolicyOnly:
+		return policy
+	default:
+		return DefaultArchived
+	}
+}
+
+func extractForksMode(loader EnvLoader) ForksMode {
+	mode := ForksMode(normalizeMode(loader.Get(ForksKey, string(DefaultForks))))
+
+	switch mode {
+	case ForksFull, ForksSkip, ForksReference:
+		return mode
+	default:
+		return DefaultForks
+	}
+}
+
+func extractCloneOrder(loader EnvLoader) CloneOrder {
+	order := CloneOrder(normalizeMode(loader.Get(CloneOrderKey, string(DefaultCloneOrder))))
+
+	switch order {
+	case CloneOrderDiscovery, CloneOrderSmallest, CloneOrderLargest:
+		return order
+	default:
+		return DefaultCloneOrder
+	}
+}
+
+// extractVisibilities returns the visibility levels of the value split by comma or space,
+// invalid levels are reported by Validate.
+func extractVisibilities(value string) []Visibility {
+	var visibilities []Visibility
+
+	for _, level := range extractGroupIDs(normalizeMode(value)) {
+		visibility := Visibility(level)
+		switch visibility {
+		case VisibilityPrivate, VisibilityInternal, VisibilityPublic:
+			if !slices.Contains(visibilities, visibility) {
+				visibilities = append(visibilities, visibility)
+			}
+		}
+	}
+
+	return visibilities
+}
+
+// extractTopics returns the topics of the value split by comma, topics may contain spaces.
+func extractTopics(value string) []string {
+	var topics []string
+
+	for _, topic := range strings.Split(value, ",") {
+		if topic = strings.TrimSpace(topic); topic != "" && !slices.Contains(topics, topic) {
+			topics = append(topics, topic)
+		}
+	}
+
+	return topics
+}
+
+func extractListFormat(loader EnvLoader) ListFormat {
+	format := ListFormat(normalizeMode(loader.Get(ListFormatKey, string(DefaultListFormat))))
+
+	switch format {
+	case ListFormatTable, ListFormatJSON, ListFormatNDJSON:
+		return format
+	default:
+		return DefaultListFormat
+	}
+}
+
+func normalizeMode(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
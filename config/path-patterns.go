@@ -0,0 +1,153 @@
+package config
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// regexPatternPrefix marks a path pattern as a regular expression instead of a glob.
+const regexPatternPrefix = "re:"
+
+// PathPattern matches project paths with namespace, e.g. gitlab-org/api/client-go.
+// A pattern is a glob, where * matches any characters except /, ? matches a single character except /
+// and ** matches any characters including /, or a regular expression prefixed with re:.
+// Globs match the whole path, regular expressions any part of it.
+type PathPattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// compilePathPattern parses the glob or the regular expression of the pattern.
+func compilePathPattern(pattern string) (*PathPattern, error) {
+	expr, isRegex := strings.CutPrefix(pattern, regexPatternPrefix)
+	if !isRegex {
+		expr = globToRegex(pattern)
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PathPattern{pattern, re}, nil
+}
+
+// globToRegex returns the anchored regular expression of the glob, **/ also matches no directories at all.
+func globToRegex(glob string) string {
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			expr.WriteString("[^/]*")
+		case glob[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+
+	expr.WriteString("$")
+
+	return expr.String()
+}
+
+// splitPathPatterns splits the value by comma or space. Commas and spaces inside brackets, braces and parentheses
+// of a regular expression don't split it, so quantifiers like {2,3} and classes like [ _] are kept.
+func splitPathPatterns(value string) []string {
+	var patterns []string
+
+	for value = strings.TrimLeftFunc(value, isPatternSeparator); value != ""; value = strings.TrimLeftFunc(value, isPatternSeparator) {
+		end := pathPatternEnd(value)
+		patterns = append(patterns, value[:end])
+		value = value[end:]
+	}
+
+	return slices.Compact(patterns)
+}
+
+// pathPatternEnd returns the length of the first pattern of the value. A regular expression with an unclosed
+// group ends at the first separator, it is reported by Validate anyway.
+func pathPatternEnd(value string) int {
+	firstSeparator := strings.IndexFunc(value, isPatternSeparator)
+	if firstSeparator < 0 {
+		firstSeparator = len(value)
+	}
+
+	if !strings.HasPrefix(value, regexPatternPrefix) {
+		return firstSeparator
+	}
+
+	depth := 0
+	// classStart is the position after the opening bracket of a character class, -1 outside of classes.
+	classStart := -1
+	escaped := false
+
+	for i, r := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case classStart >= 0:
+			if r == '^' && i == classStart {
+				classStart++
+			} else if r == ']' && i > classStart {
+				// A bracket right after the opening one is a literal.
+				classStart = -1
+				depth--
+			}
+		case r == '[':
+			classStart = i + 1
+			depth++
+		case r == '(' || r == '{':
+			depth++
+		case (r == ')' || r == '}') && depth > 0:
+			depth--
+		case depth == 0 && isPatternSeparator(r):
+			return i
+		}
+	}
+
+	if depth > 0 {
+		return firstSeparator
+	}
+
+	return len(value)
+}
+
+func isPatternSeparator(r rune) bool {
+	return r == ',' || unicode.IsSpace(r)
+}
+
+// extractPathPatterns returns the patterns of the value split by comma or space,
+// invalid patterns are reported by Validate.
+func extractPathPatterns(value string) []*PathPattern {
+	var patterns []*PathPattern
+
+	for _, pattern := range splitPathPatterns(value) {
+		if compiled, err := compilePathPattern(pattern); err == nil {
+			patterns = append(patterns, compiled)
+		}
+	}
+
+	return patterns
+}
+
+// Match reports whether the project path matches the pattern.
+func (p *PathPattern) Match(path string) bool {
+	return p.re.MatchString(path)
+}
+
+func (p *PathPattern) String() string {
+	return p.pattern
+}
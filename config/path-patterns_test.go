@@ -0,0 +1,90 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPathPattern_Match(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"**/sandbox-*", "org/sandbox-alice", true},
+		{"**/sandbox-*", "org/team/sandbox-bob", true},
+		{"**/sandbox-*", "sandbox-root", true},
+		{"**/sandbox-*", "org/sandbox-alice/app", false},
+		{"org/legacy/**", "org/legacy/app", true},
+		{"org/legacy/**", "org/legacy/sub/app", true},
+		{"org/legacy/**", "org/legacy-app", false},
+		{"org/*", "org/app", true},
+		{"org/*", "org/sub/app", false},
+		{"org/app?", "org/app1", true},
+		{"org/app.js", "org/appxjs", false},
+		{"re:^org/(api|web)$", "org/api", true},
+		{"re:^org/(api|web)$", "org/cli", false},
+		{"re:archive", "org/archive-2020/app", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.path, func(t *testing.T) {
+			pattern, err := compilePathPattern(test.pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if pattern.Match(test.path) != test.expected {
+				t.Errorf("expected %s to match %s: %t", test.pattern, test.path, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractPathPatterns(t *testing.T) {
+	var patterns []string
+	for _, pattern := range extractPathPatterns("**/sandbox-*, re:[a- org/legacy/**") {
+		patterns = append(patterns, pattern.String())
+	}
+
+	expected := []string{"**/sandbox-*", "org/legacy/**"}
+	if !slices.Equal(patterns, expected) {
+		t.Errorf("expected patterns %v, got %v", expected, patterns)
+	}
+}
+
+func TestSplitPathPatterns(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []string
+	}{
+		{"org/**, **/sandbox-*  other/*", []string{"org/**", "**/sandbox-*", "other/*"}},
+		{`re:^org/[a-z]{2,3}/app$, org/legacy/**`, []string{`re:^org/[a-z]{2,3}/app$`, "org/legacy/**"}},
+		{`re:^org/(api|cli tools)/ re:[ ,]x`, []string{`re:^org/(api|cli tools)/`, `re:[ ,]x`}},
+		{`re:a\,b,c`, []string{`re:a\,b`, "c"}},
+		{`re:[],] re:\[a, b`, []string{`re:[],]`, `re:\[a`, "b"}},
+		{"re:[a- org/legacy/**", []string{"re:[a-", "org/legacy/**"}},
+		{" ,org/** org/**", []string{"org/**"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			if patterns := splitPathPatterns(test.value); !slices.Equal(patterns, test.expected) {
+				t.Errorf("expected patterns %q, got %q", test.expected, patterns)
+			}
+		})
+	}
+}
+
+func TestExtractPathPatterns_Quantifier(t *testing.T) {
+	patterns := extractPathPatterns(`re:^org/[a-z]{2,3}/, org/legacy/**`)
+	if len(patterns) != 2 {
+		t.Fatalf("expected 2 patterns, got %v", patterns)
+	}
+
+	for path, expected := range map[string]bool{"org/ab/app": true, "org/abc/app": true, "org/abcd/app": false} {
+		if patterns[0].Match(path) != expected {
+			t.Errorf("expected %s to match %s: %t", patterns[0], path, expected)
+		}
+	}
+}
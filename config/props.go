@@ -14,12 +14,119 @@ var (
 	UseSSHKey     = "RE_USE_SSH"
 	DefaultUseSSH = "false"
 
+	CloneModeKey     = "RE_CLONE_MODE"
+	DefaultCloneMode = CloneModeBare
+
+	// CloneBareKey is deprecated, it is used only when CloneModeKey is not set.
 	CloneBareKey     = "RE_CLONE_BARE"
 	DefaultCloneBare = "true"
 
+	SyncExistingKey     = "RE_SYNC_EXISTING"
+	DefaultSyncExisting = "false"
+
+	StateFileKey     = "RE_STATE_FILE"
+	DefaultStateFile = ".gitlab-repo-extractor.state.json"
+
+	ResumeKey     = "RE_RESUME"
+	DefaultResume = "false"
+
+	JournalFileKey     = "RE_JOURNAL_FILE"
+	DefaultJournalFile = ".gitlab-repo-extractor.journal"
+
+	PruneModeKey     = "RE_PRUNE"
+	DefaultPruneMode = PruneModeOff
+
+	QuarantineDirKey     = "RE_QUARANTINE_DIR"
+	DefaultQuarantineDir = ".quarantine"
+
+	QuarantineRetentionKey     = "RE_QUARANTINE_RETENTION_DAYS"
+	DefaultQuarantineRetention = 30
+
+	StagingDirKey     = "RE_STAGING_DIR"
+	DefaultStagingDir = ".staging"
+
+	MaxFailurePercentKey     = "RE_MAX_FAILURE_PERCENT"
+	DefaultMaxFailurePercent = 0
+
+	DryRunKey     = "RE_DRY_RUN"
+	DefaultDryRun = "false"
+
+	ListFormatKey     = "RE_LIST_FORMAT"
+	DefaultListFormat = ListFormatTable
+
+	// InstancesKey is a list of instance names, every instance is configured with RE_INSTANCE_<NAME>_* variables.
+	InstancesKey = "RE_INSTANCES"
+	// OutputSubDirSuffix of an instance is its subdirectory of the output directory, the instance name by default.
+	OutputSubDirSuffix = "OUTPUT_SUBDIR"
+
 	GroupIDsKey     = "RE_GROUP_IDS"
 	SkipGroupIDsKey = "RE_SKIP_GROUP_IDS"
 
+	ProjectIDsKey = "RE_PROJECT_IDS"
+	// ProjectIDsFileKey is a file with a project ID or path per line, its projects are added to ProjectIDsKey.
+	ProjectIDsFileKey = "RE_PROJECT_IDS_FILE"
+
+	MembershipKey     = "RE_MEMBERSHIP"
+	DefaultMembership = "false"
+
+	// MinAccessLevelKey is a role name or a numeric GitLab access level.
+	MinAccessLevelKey = "RE_MIN_ACCESS_LEVEL"
+
+	// AdminModeKey lists all projects of the instance instead of walking groups, it needs an administrator token.
+	AdminModeKey     = "RE_ADMIN_MODE"
+	DefaultAdminMode = "false"
+
+	WithSharedKey     = "RE_WITH_SHARED"
+	DefaultWithShared = "false"
+
+	SharedPlacementKey     = "RE_SHARED_PLACEMENT"
+	DefaultSharedPlacement = SharedPlacementNamespace
+
+	DiscoveryStrategyKey     = "RE_DISCOVERY_STRATEGY"
+	DefaultDiscoveryStrategy = DiscoveryStrategyWalk
+
+	APIBackendKey     = "RE_API_BACKEND"
+	DefaultAPIBackend = APIBackendREST
+
+	// IncludePathsKey and ExcludePathsKey are path patterns of projects, see PathPattern.
+	IncludePathsKey = "RE_INCLUDE_PATHS"
+	ExcludePathsKey = "RE_EXCLUDE_PATHS"
+
+	// IncludeVisibilityKey and ExcludeVisibilityKey are visibility levels of projects: private, internal or public.
+	IncludeVisibilityKey = "RE_INCLUDE_VISIBILITY"
+	ExcludeVisibilityKey = "RE_EXCLUDE_VISIBILITY"
+
+	// IncludeTopicsKey and ExcludeTopicsKey are topics of projects split by comma, topics are matched case-insensitively.
+	IncludeTopicsKey = "RE_INCLUDE_TOPICS"
+	ExcludeTopicsKey = "RE_EXCLUDE_TOPICS"
+
+	ArchivedKey     = "RE_ARCHIVED"
+	DefaultArchived = ArchivedPolicyInclude
+
+	// ArchivedOutputDirKey is a separate output directory of archived projects, relative paths are resolved
+	// against the output directory. Archived projects are cloned into the output directory when it is empty.
+	ArchivedOutputDirKey = "RE_ARCHIVED_OUTPUT_DIR"
+
+	ForksKey     = "RE_FORKS"
+	DefaultForks = ForksFull
+
+	// ActiveSinceKey limits projects to those active since a date or within a relative window, e.g. 90d.
+	ActiveSinceKey = "RE_ACTIVE_SINCE"
+
+	// StatisticsKey fetches the repository sizes of projects, MaxRepoSizeKey and CloneOrderKey need them too.
+	StatisticsKey     = "RE_STATISTICS"
+	DefaultStatistics = "false"
+
+	// MaxRepoSizeKey is the size of the largest repository which is cloned, e.g. 500MB or 2GiB.
+	MaxRepoSizeKey = "RE_MAX_REPO_SIZE"
+
+	CloneOrderKey     = "RE_CLONE_ORDER"
+	DefaultCloneOrder = CloneOrderDiscovery
+
+	UserIDsKey = "RE_USER_IDS"
+	// CurrentUserAlias in UserIDsKey stands for the owner of the access token.
+	CurrentUserAlias = "@me"
+
 	RetryDelayKey     = "RE_RETRY_DELAY_SECONDS"
 	DefaultRetryDelay = 2
 
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// errInvalidSize is returned for values which are not a size.
+var errInvalidSize = errors.New("invalid size")
+
+// sizeUnits are the units of sizes, decimal and binary ones, longer suffixes first.
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"kib", 1 << 10},
+	{"mib", 1 << 20},
+	{"gib", 1 << 30},
+	{"tib", 1 << 40},
+	{"kb", 1e3},
+	{"mb", 1e6},
+	{"gb", 1e9},
+	{"tb", 1e12},
+	{"b", 1},
+}
+
+// parseSize returns the number of bytes of the value, a number with an optional unit, e.g. 500MB or 2GiB.
+// It returns 0 for an empty value.
+func parseSize(value string) (int64, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, multiplier = strings.TrimSpace(number), unit.multiplier
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 || math.IsInf(number, 0) || math.IsNaN(number) {
+		return 0, errInvalidSize
+	}
+
+	return int64(number * float64(multiplier)), nil
+}
+
+// extractMaxRepoSize returns the size of MaxRepoSizeKey in bytes, invalid values are reported by Validate.
+func extractMaxRepoSize(loader EnvLoader) int64 {
+	size, _ := parseSize(loader.Get(MaxRepoSizeKey))
+	return size
+}
@@ -0,0 +1,34 @@
+package config
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		invalid  bool
+	}{
+		{"", 0, false},
+		{"1048576", 1 << 20, false},
+		{"500MB", 500e6, false},
+		{"2 GiB", 2 << 30, false},
+		{"1.5kb", 1500, false},
+		{"10b", 10, false},
+		{"huge", 0, true},
+		{"-1GB", 0, true},
+		{"infMB", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			size, err := parseSize(test.value)
+			if (err != nil) != test.invalid {
+				t.Fatalf("expected invalid %t, got error %v", test.invalid, err)
+			}
+
+			if size != test.expected {
+				t.Errorf("expected %d, got %d", test.expected, size)
+			}
+		})
+	}
+}
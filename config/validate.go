@@ -0,0 +1,237 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorMissingAccessToken indicates that the GitLab access token is not configured.
+var ErrorMissingAccessToken = errors.New("GitLab access token is required, set " + GitlabTokenKey)
+
+// ErrorPruneWithoutOutputDir indicates that repositories would be pruned from the working directory.
+var ErrorPruneWithoutOutputDir = errors.New("pruning repositories requires an output directory, set " + OutputDirKey)
+
+// ErrorInvalidValue is an error type that indicates an unsupported value of a configuration variable.
+type ErrorInvalidValue struct {
+	key   string
+	value string
+}
+
+func (e *ErrorInvalidValue) Error() string {
+	return fmt.Sprintf("invalid value of %s: %q", e.key, e.value)
+}
+
+// ErrorReadListFile is an error type that indicates a failure to read a list file of a configuration variable.
+type ErrorReadListFile struct {
+	key           string
+	path          string
+	originalError error
+}
+
+func (e *ErrorReadListFile) Error() string {
+	return fmt.Sprintf("failed to read %s file %s: %v", e.key, e.path, e.originalError)
+}
+
+// intKeys are the variables which must be integers when set.
+var intKeys = []string{
+	MaxWorkersKey,
+	MaxRetriesKey,
+	RetryDelayKey,
+	QuarantineRetentionKey,
+	MaxFailurePercentKey,
+}
+
+// findInvalidValues returns the variables whose values would be silently replaced by defaults.
+func findInvalidValues(loader EnvLoader) []error {
+	var errs []error
+
+	for _, key := range intKeys {
+		if value := loader.Get(key); value != "" {
+			if _, err := strconv.Atoi(value); err != nil {
+				errs = append(errs, &ErrorInvalidValue{key, value})
+			}
+		}
+	}
+
+	if value := loader.Get(CloneModeKey); value != "" &&
+		!slices.Contains([]CloneMode{CloneModeWorking, CloneModeBare, CloneModeMirror}, CloneMode(normalizeMode(value))) {
+		errs = append(errs, &ErrorInvalidValue{CloneModeKey, value})
+	}
+
+	if value := loader.Get(PruneModeKey); value != "" &&
+		!slices.Contains([]PruneMode{PruneModeOff, PruneModeDryRun, PruneModeQuarantine, PruneModeDelete}, PruneMode(normalizeMode(value))) {
+		errs = append(errs, &ErrorInvalidValue{PruneModeKey, value})
+	}
+
+	if path := loader.Get(ProjectIDsFileKey); path != "" {
+		if _, err := readListFile(path); err != nil {
+			errs = append(errs, &ErrorReadListFile{ProjectIDsFileKey, path, err})
+		}
+	}
+
+	if value := loader.Get(SharedPlacementKey); value != "" &&
+		!slices.Contains([]SharedPlacement{SharedPlacementNamespace, SharedPlacementGroup}, SharedPlacement(normalizeMode(value))) {
+		errs = append(errs, &ErrorInvalidValue{SharedPlacementKey, value})
+	}
+
+	if value := loader.Get(DiscoveryStrategyKey); value != "" &&
+		!slices.Contains([]DiscoveryStrategy{DiscoveryStrategyWalk, DiscoveryStrategySubgroups}, DiscoveryStrategy(normalizeMode(value))) {
+		errs = append(errs, &ErrorInvalidValue{DiscoveryStrategyKey, value})
+	}
+
+	if value := loader.Get(APIBackendKey); value != "" &&
+		!slices.Contains([]APIBackend{APIBackendREST, APIBackendGraphQL}, APIBackend(normalizeMode(value))) {
+		errs = append(errs, &ErrorInvalidValue{APIBackendKey, value})
+	}
+
+	if value := loader.Get(MinAccessLevelKey); value != "" && extractAccessLevel(value) <= 0 {
+		errs = append(errs, &ErrorInvalidValue{MinAccessLevelKey, value})
+	}
+
+	if value := loader.Get(ArchivedKey); value != "" &&
+		!slices.Contains([]ArchivedPolicy{ArchivedPolicyInclude, ArchivedPolicySkip, ArchivedPolicyOnly}, ArchivedPolicy(normalizeMode(value))) {
+		errs = append(errs, &ErrorInvalidValue{ArchivedKey, value})
+	}
+
+	if value := loader.Get(ForksKey); value != "" &&
+		!slices.Contains([]ForksMode{ForksFull, ForksSkip, ForksReference}, ForksMode(normalizeMode(value))) {
+		errs = append(errs, &ErrorInvalidValue{ForksKey, value})
+	}
+
+	if value := loader.Get(ActiveSinceKey); value != "" {
+		if _, err := parseActiveSince(value, time.Now()); err != nil {
+			errs = append(errs, &ErrorInvalidValue{ActiveSinceKey, value})
+		}
+	}
+
+	if value := loader.Get(MaxRepoSizeKey); value != "" {
+		if _, err := parseSize(value); err != nil {
+			errs = append(errs, &ErrorInvalidValue{MaxRepoSizeKey, value})
+		}
+	}
+
+	if value := loader.Get(CloneOrderKey); value != "" &&
+		!slices.Contains([]CloneOrder{CloneOrderDiscovery, CloneOrderSmallest, CloneOrderLargest}, CloneOrder(normalizeMode(value))) {
+		errs = append(errs, &ErrorInvalidValue{CloneOrderKey, value})
+	}
+
+	for _, key := range []string{IncludePathsKey, ExcludePathsKey} {
+		for _, pattern := range splitPathPatterns(loader.Get(key)) {
+			if _, err := compilePathPattern(pattern); err != nil {
+				errs = append(errs, &ErrorInvalidValue{key, pattern})
+			}
+		}
+	}
+
+	for _, key := range []string{IncludeVisibilityKey, ExcludeVisibilityKey} {
+		for _, level := range extractGroupIDs(loader.Get(key)) {
+			visibility := Visibility(normalizeMode(level))
+			if !slices.Contains([]Visibility{VisibilityPrivate, VisibilityInternal, VisibilityPublic}, visibility) {
+				errs = append(errs, &ErrorInvalidValue{key, level})
+			}
+		}
+	}
+
+	if value := loader.Get(ListFormatKey); value != "" &&
+		!slices.Contains([]ListFormat{ListFormatTable, ListFormatJSON, ListFormatNDJSON}, ListFormat(normalizeMode(value))) {
+		errs = append(errs, &ErrorInvalidValue{ListFormatKey, value})
+	}
+
+	return errs
+}
+
+// Validate reports every problem of the configuration which prevents a meaningful run.
+// With RE_INSTANCES every instance is validated instead.
+func (c *Config) Validate() error {
+	if len(c.instances) > 0 {
+		var errs []error
+		files := map[string]struct{}{}
+
+		for _, instance := range c.instances {
+			err := instance.Validate()
+
+			// The state and the journal are keyed by project IDs, which repeat across instances.
+			for _, file := range []ErrorInvalidValue{
+				{StateFileKey, filepath.Clean(instance.GetStateFile())},
+				{JournalFileKey, filepath.Clean(instance.GetJournalFile())},
+			} {
+				if _, ok := files[file.value]; ok {
+					err = errors.Join(err, &file)
+				}
+				files[file.value] = struct{}{}
+			}
+
+			if err != nil {
+				errs = append(errs, &ErrorInstance{instance.instanceName, err})
+			}
+		}
+
+		return errors.Join(errs...)
+	}
+
+	errs := slices.Clone(c.invalidValues)
+
+	if c.accessToken == "" {
+		errs = append(errs, ErrorMissingAccessToken)
+	}
+
+	if gitLabURL, err := url.Parse(c.gitLabURL); err != nil || (gitLabURL.Scheme != "http" && gitLabURL.Scheme != "https") || gitLabURL.Host == "" {
+		errs = append(errs, &ErrorInvalidValue{GitlabURLKey, c.gitLabURL})
+	}
+
+	if c.maxWorkers < 1 {
+		errs = append(errs, &ErrorInvalidValue{MaxWorkersKey, strconv.Itoa(c.maxWorkers)})
+	}
+
+	if c.maxRetries < 1 {
+		errs = append(errs, &ErrorInvalidValue{MaxRetriesKey, strconv.Itoa(c.maxRetries)})
+	}
+
+	if c.maxFailurePercent < 0 || c.maxFailurePercent > 100 {
+		errs = append(errs, &ErrorInvalidValue{MaxFailurePercentKey, strconv.Itoa(c.maxFailurePercent)})
+	}
+
+	if c.outputDir == "" && (c.pruneMode == PruneModeQuarantine || c.pruneMode == PruneModeDelete) {
+		errs = append(errs, ErrorPruneWithoutOutputDir)
+	}
+
+	if c.stagingOverlapsOutput() {
+		errs = append(errs, &ErrorInvalidValue{StagingDirKey, c.stagingDir})
+	}
+
+	return errors.Join(errs...)
+}
+
+// stagingOverlapsOutput reports whether a staging directory is or contains the output, archived output
+// or quarantine directory, whose repositories the cleanup of the staging directory would reach.
+func (c *Config) stagingOverlapsOutput() bool {
+	dirs := []string{c.GetOutputDir(), c.GetArchivedOutputDir(), c.GetQuarantineDir()}
+
+	for _, stagingDir := range c.GetStagingDirs() {
+		for _, dir := range dirs {
+			if dir != "" && isSubPath(stagingDir, dir) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isSubPath reports whether path is the same as or inside parent.
+func isSubPath(parent, path string) bool {
+	parent, errParent := filepath.Abs(parent)
+	path, errPath := filepath.Abs(path)
+	if errParent != nil || errPath != nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(parent, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		envs     map[string]string
+		expected []error
+	}{
+		{
+			name: "valid configuration",
+			envs: map[string]string{
+				GitlabTokenKey: "token",
+			},
+		},
+		{
+			name:     "missing access token",
+			envs:     map[string]string{},
+			expected: []error{ErrorMissingAccessToken},
+		},
+		{
+			name: "unsupported values",
+			envs: map[string]string{
+				GitlabTokenKey:       "token",
+				GitlabURLKey:         "gitlab.example.com",
+				CloneModeKey:         "shallow",
+				PruneModeKey:         "yes",
+				MaxWorkersKey:        "many",
+				MaxRetriesKey:        "0",
+				MaxFailurePercentKey: "150",
+				ListFormatKey:        "yaml",
+				MinAccessLevelKey:    "admin",
+				SharedPlacementKey:   "root",
+				DiscoveryStrategyKey: "bfs",
+				APIBackendKey:        "soap",
+				ExcludePathsKey:      "org/** re:[a-",
+				ArchivedKey:          "never",
+				ForksKey:             "shallow",
+				ActiveSinceKey:       "recently",
+				MaxRepoSizeKey:       "huge",
+				CloneOrderKey:        "random",
+				IncludeVisibilityKey: "private,secret",
+			},
+			expected: []error{
+				&ErrorInvalidValue{MaxWorkersKey, "many"},
+				&ErrorInvalidValue{CloneModeKey, "shallow"},
+				&ErrorInvalidValue{PruneModeKey, "yes"},
+				&ErrorInvalidValue{SharedPlacementKey, "root"},
+				&ErrorInvalidValue{DiscoveryStrategyKey, "bfs"},
+				&ErrorInvalidValue{APIBackendKey, "soap"},
+				&ErrorInvalidValue{MinAccessLevelKey, "admin"},
+				&ErrorInvalidValue{ArchivedKey, "never"},
+				&ErrorInvalidValue{ForksKey, "shallow"},
+				&ErrorInvalidValue{ActiveSinceKey, "recently"},
+				&ErrorInvalidValue{MaxRepoSizeKey, "huge"},
+				&ErrorInvalidValue{CloneOrderKey, "random"},
+				&ErrorInvalidValue{ExcludePathsKey, "re:[a-"},
+				&ErrorInvalidValue{IncludeVisibilityKey, "secret"},
+				&ErrorInvalidValue{ListFormatKey, "yaml"},
+				&ErrorInvalidValue{GitlabURLKey, "gitlab.example.com"},
+				&ErrorInvalidValue{MaxRetriesKey, "0"},
+				&ErrorInvalidValue{MaxFailurePercentKey, "150"},
+			},
+		},
+		{
+			name: "pruning without an output directory",
+			envs: map[string]string{
+				GitlabTokenKey: "token",
+				PruneModeKey:   "delete",
+			},
+			expected: []error{ErrorPruneWithoutOutputDir},
+		},
+		{
+			name: "prune dry run without an output directory",
+			envs: map[string]string{
+				GitlabTokenKey: "token",
+				PruneModeKey:   "dry-run",
+			},
+		},
+		{
+			name: "staging directory of the output directory",
+			envs: map[string]string{
+				GitlabTokenKey: "token",
+				OutputDirKey:   "/backup",
+				StagingDirKey:  "/backup",
+			},
+			expected: []error{&ErrorInvalidValue{StagingDirKey, "/backup"}},
+		},
+		{
+			name: "staging directory containing the quarantine directory",
+			envs: map[string]string{
+				GitlabTokenKey:   "token",
+				OutputDirKey:     "/backup",
+				StagingDirKey:    "/tmp",
+				QuarantineDirKey: "/tmp/quarantine",
+			},
+			expected: []error{&ErrorInvalidValue{StagingDirKey, "/tmp"}},
+		},
+		{
+			name: "staging directory containing the archived output directory",
+			envs: map[string]string{
+				GitlabTokenKey:       "token",
+				OutputDirKey:         "/backup",
+				StagingDirKey:        "/mnt",
+				ArchivedOutputDirKey: "/mnt/archive",
+			},
+			expected: []error{&ErrorInvalidValue{StagingDirKey, "/mnt"}},
+		},
+		{
+			name: "staging directory inside the output directory",
+			envs: map[string]string{
+				GitlabTokenKey:       "token",
+				OutputDirKey:         "/backup",
+				ArchivedOutputDirKey: "/archive",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := NewConfig(NewMemoryEnvLoader(test.envs)).Validate()
+
+			expected := errors.Join(test.expected...)
+			if expected == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != expected.Error() {
+				t.Errorf("expected error:\n%v\ngot:\n%v", expected, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_ListFile(t *testing.T) {
+	err := NewConfig(NewMemoryEnvLoader(map[string]string{
+		GitlabTokenKey:    "token",
+		ProjectIDsFileKey: "/missing/projects.txt",
+	})).Validate()
+
+	var readErr *ErrorReadListFile
+	if !errors.As(err, &readErr) || readErr.path != "/missing/projects.txt" {
+		t.Errorf("expected read list file error, got: %v", err)
+	}
+}
+
+func TestErrorReadListFile_Error(t *testing.T) {
+	err := &ErrorReadListFile{ProjectIDsFileKey, "projects.txt", errors.New("fail")}
+	want := "failed to read RE_PROJECT_IDS_FILE file projects.txt: fail"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorInvalidValue_Error(t *testing.T) {
+	err := &ErrorInvalidValue{CloneModeKey, "shallow"}
+	want := `invalid value of RE_CLONE_MODE: "shallow"`
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
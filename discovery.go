@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+)
+
+// discoverProjects starts every configured discovery source and merges their projects and errors,
+// a project found by several sources is passed only once.
+// Groups are walked when RE_GROUP_IDS is set or no other source is configured, which keeps the default
+// of backing up all available groups. RE_ADMIN_MODE without RE_GROUP_IDS replaces that walk with the list of all
+// projects of the instance. The skipped groups are resolved once by the sources which leave them out.
+// The returned groups channel must be drained by the caller.
+func discoverProjects(
+	ctx context.Context,
+	cfg *config.Config,
+	client DiscoveryService,
+	skipped *skipGroupsResolver,
+) (<-chan *Group, <-chan *Project, <-chan error) {
+	var projectChans []<-chan *Project
+	var errChans []<-chan error
+
+	var groups <-chan *Group
+
+	if walksGroups(cfg) {
+		groupsChan, groupErrsChan := fetchGroups(ctx, client, cfg, skipped)
+		groupsChans := teeChan(ctx, groupsChan, 2)
+
+		var projectsChan <-chan *Project
+		var projectErrsChan <-chan error
+		if cfg.GetDiscoveryStrategy() == config.DiscoveryStrategySubgroups {
+			projectsChan, projectErrsChan = proceedGroupTrees(ctx, client, cfg, groupsChans[0])
+		} else {
+			projectsChan, projectErrsChan = proceedGroups(ctx, client, cfg, groupsChans[0])
+		}
+
+		groups = groupsChans[1]
+		projectChans = append(projectChans, projectsChan)
+		errChans = append(errChans, groupErrsChan, projectErrsChan)
+	} else {
+		noGroups := make(chan *Group)
+		close(noGroups)
+		groups = noGroups
+	}
+
+	if userIDs := cfg.GetUserIDs(); len(userIDs) > 0 {
+		projectsChan, errsChan := fetchUserProjects(ctx, client, client, userIDs, newProjectsQuery(cfg))
+
+		projectChans = append(projectChans, projectsChan)
+		errChans = append(errChans, errsChan)
+	}
+
+	if projectIDs := cfg.GetProjectIDs(); len(projectIDs) > 0 {
+		projectsChan, errsChan := fetchProjectsByIDs(ctx, client, projectIDs)
+
+		projectChans = append(projectChans, projectsChan)
+		errChans = append(errChans, errsChan)
+	}
+
+	if listsAllProjects(cfg) {
+		projectsChan, errsChan := fetchAllProjects(ctx, client, skipped, newProjectsQuery(cfg))
+
+		projectChans = append(projectChans, projectsChan)
+		errChans = append(errChans, errsChan)
+	}
+
+	if cfg.GetMembership() {
+		projectsChan, errsChan := fetchMemberProjects(ctx, client, cfg.GetMinAccessLevel(), newProjectsQuery(cfg))
+
+		projectChans = append(projectChans, projectsChan)
+		errChans = append(errChans, errsChan)
+	}
+
+	return groups, uniqueProjects(ctx, mergeChans(ctx, projectChans...)), mergeChans(ctx, errChans...)
+}
+
+// walksGroups reports whether projects are discovered by walking the groups.
+func walksGroups(cfg *config.Config) bool {
+	return len(cfg.GetGroupIDs()) > 0 || !hasExplicitSources(cfg)
+}
+
+// listsAllProjects reports whether projects are discovered by listing all projects of the instance.
+func listsAllProjects(cfg *config.Config) bool {
+	return cfg.GetAdminMode() && len(cfg.GetGroupIDs()) == 0
+}
+
+// hasExplicitSources reports whether projects are discovered by sources other than groups.
+func hasExplicitSources(cfg *config.Config) bool {
+	return len(cfg.GetUserIDs()) > 0 || len(cfg.GetProjectIDs()) > 0 || cfg.GetMembership() || cfg.GetAdminMode()
+}
+
+// uniqueProjects passes only the first occurrence of every project ID.
+// Shared projects are held back until all projects are discovered, so a project owned by a discovered
+// namespace is always placed there rather than under a group it is shared with.
+func uniqueProjects(ctx context.Context, projectsChan <-chan *Project) <-chan *Project {
+	out := make(chan *Project)
+
+	go func() {
+		defer close(out)
+
+		seen := newIDSet()
+		var shared []*Project
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case project, ok := <-projectsChan:
+				if !ok {
+					for _, project := range shared {
+						if !seen.add(project.id) {
+							continue
+						}
+
+						select {
+						case <-ctx.Done():
+							return
+						case out <- project:
+						}
+					}
+					return
+				}
+				if project == nil {
+					continue
+				}
+
+				if project.shared {
+					shared = append(shared, project)
+					continue
+				}
+
+				if !seen.add(project.id) {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case out <- project:
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestDiscoverProjects(t *testing.T) {
+	client := &FakeGitlabDiscovery{
+		FakeGitlabGroups: NewFakeGitlab(map[string]*gitlab.Group{
+			"root": {ID: 1, FullPath: "root"},
+		}),
+		FakeGitlabProjects: &FakeGitlabProjects{
+			projects: map[int]map[int]*gitlab.Project{
+				1: {10: {ID: 10, PathWithNamespace: "root/app"}},
+			},
+			userProjects: map[string][]*gitlab.Project{
+				"alice": {{ID: 20, PathWithNamespace: "alice/prototype"}},
+			},
+			member: []*gitlab.Project{
+				{ID: 10, PathWithNamespace: "root/app"},
+				{ID: 40, PathWithNamespace: "hidden-parent/member"},
+			},
+			byIDs: map[string]*gitlab.Project{
+				"10":             {ID: 10, PathWithNamespace: "root/app"},
+				"other/selected": {ID: 30, PathWithNamespace: "other/selected"},
+			},
+		},
+		FakeGitlabUsers: &FakeGitlabUsers{},
+	}
+
+	tests := []struct {
+		name     string
+		envs     map[string]string
+		expected []string
+	}{
+		{
+			name:     "groups only",
+			envs:     map[string]string{config.GroupIDsKey: "root"},
+			expected: []string{"root/app"},
+		},
+		{
+			name:     "groups listed with subgroups",
+			envs:     map[string]string{config.GroupIDsKey: "root", config.DiscoveryStrategyKey: "subgroups"},
+			expected: []string{"root/app"},
+		},
+		{
+			name:     "admin mode is limited by group IDs",
+			envs:     map[string]string{config.GroupIDsKey: "root", config.AdminModeKey: "true"},
+			expected: []string{"root/app"},
+		},
+		{
+			name:     "users only do not walk all groups",
+			envs:     map[string]string{config.UserIDsKey: "alice"},
+			expected: []string{"alice/prototype"},
+		},
+		{
+			name:     "groups and users",
+			envs:     map[string]string{config.GroupIDsKey: "root", config.UserIDsKey: "alice"},
+			expected: []string{"alice/prototype", "root/app"},
+		},
+		{
+			name:     "projects only do not walk all groups",
+			envs:     map[string]string{config.ProjectIDsKey: "other/selected"},
+			expected: []string{"other/selected"},
+		},
+		{
+			name:     "member projects do not walk all groups",
+			envs:     map[string]string{config.MembershipKey: "true"},
+			expected: []string{"hidden-parent/member", "root/app"},
+		},
+		{
+			name:     "projects found by several sources are passed once",
+			envs:     map[string]string{config.GroupIDsKey: "root", config.ProjectIDsKey: "10,other/selected"},
+			expected: []string{"other/selected", "root/app"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.NewConfig(config.NewMemoryEnvLoader(test.envs))
+
+			groupsChan, projectsChan, errsChan := discoverProjects(context.Background(), cfg, client, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
+			go func() {
+				for range groupsChan {
+				}
+			}()
+
+			paths, errs := collectProjects(t, projectsChan, errsChan)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			if !slices.Equal(paths, test.expected) {
+				t.Errorf("expected projects %v, got %v", test.expected, paths)
+			}
+		})
+	}
+}
+
+func TestUniqueProjects_SharedProjectsYield(t *testing.T) {
+	group := &Group{id: 1, fullPath: "root"}
+	in := make(chan *Project, 3)
+	in <- &Project{id: 20, pathWithNamespace: "other/lib", group: group, shared: true}
+	in <- &Project{id: 20, pathWithNamespace: "other/lib"}
+	in <- &Project{id: 30, pathWithNamespace: "third/tool", group: group, shared: true}
+	close(in)
+
+	received := map[int]bool{}
+	for project := range uniqueProjects(context.Background(), in) {
+		if _, ok := received[project.id]; ok {
+			t.Fatalf("project %d passed twice", project.id)
+		}
+		received[project.id] = project.shared
+	}
+
+	expected := map[int]bool{20: false, 30: true}
+	if !maps.Equal(received, expected) {
+		t.Errorf("expected projects %v, got %v", expected, received)
+	}
+}
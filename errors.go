@@ -53,6 +53,71 @@ func (e *ErrorProjectsFetching) Error() string {
 	return fmt.Sprintf("failed to fetch projects for group %d: %v", e.groupID, e.originalError)
 }
 
+// ErrorProjectFetching is an error type that indicates a failure to fetch a project by its ID or path.
+type ErrorProjectFetching struct {
+	projectID     string
+	originalError error
+}
+
+func (e *ErrorProjectFetching) Error() string {
+	return fmt.Sprintf("failed to fetch project %s: %v", e.projectID, e.originalError)
+}
+
+// ErrorMemberProjectsFetching is an error type that indicates a failure to fetch projects the token is a member of.
+type ErrorMemberProjectsFetching struct {
+	originalError error
+}
+
+func (e *ErrorMemberProjectsFetching) Error() string {
+	return fmt.Sprintf("failed to fetch member projects: %v", e.originalError)
+}
+
+// ErrorAllProjectsFetching is an error type that indicates a failure to list all projects of the instance.
+type ErrorAllProjectsFetching struct {
+	originalError error
+}
+
+func (e *ErrorAllProjectsFetching) Error() string {
+	return fmt.Sprintf("failed to fetch all projects: %v", e.originalError)
+}
+
+// ErrorUserProjectsFetching is an error type that indicates a failure to fetch personal projects of a user.
+type ErrorUserProjectsFetching struct {
+	userID        string
+	originalError error
+}
+
+func (e *ErrorUserProjectsFetching) Error() string {
+	return fmt.Sprintf("failed to fetch projects of user %s: %v", e.userID, e.originalError)
+}
+
+// ErrorGraphQLGroupNotFound is an error type that indicates a group queried with the GraphQL API doesn't exist.
+type ErrorGraphQLGroupNotFound struct {
+	fullPath string
+}
+
+func (e *ErrorGraphQLGroupNotFound) Error() string {
+	return fmt.Sprintf("group %s not found", e.fullPath)
+}
+
+// ErrorGraphQLQuery is an error type that indicates errors reported in a GraphQL response.
+type ErrorGraphQLQuery struct {
+	messages []string
+}
+
+func (e *ErrorGraphQLQuery) Error() string {
+	return fmt.Sprintf("GraphQL query failed: %s", strings.Join(e.messages, "; "))
+}
+
+// ErrorGraphQLID is an error type that indicates a GraphQL global ID without a numeric ID.
+type ErrorGraphQLID struct {
+	globalID string
+}
+
+func (e *ErrorGraphQLID) Error() string {
+	return fmt.Sprintf("invalid GraphQL ID %s", e.globalID)
+}
+
 // ErrorDirExists is an error type that indicates a directory already exists.
 type ErrorDirExists string
 
@@ -101,11 +166,122 @@ func (e *ErrorFailedToCloneProject) Error() string {
 	return fmt.Sprintf("failed to clone project (%s): %v\nOutput:\n%s", e.projectDir, e.originalError, e.output)
 }
 
+// ErrorIntegrityCheck is an error type that indicates a new clone of a project failed the integrity check.
+type ErrorIntegrityCheck struct {
+	projectDir    string
+	originalError error
+	output        []byte
+}
+
+func (e *ErrorIntegrityCheck) Error() string {
+	return fmt.Sprintf("integrity check failed for project (%s): %v\nOutput:\n%s", e.projectDir, e.originalError, e.output)
+}
+
+// ErrorFailedToUpdateProject is an error type that indicates a failure to update an existing clone of a project.
+type ErrorFailedToUpdateProject struct {
+	projectDir    string
+	originalError error
+	output        []byte
+}
+
+func (e *ErrorFailedToUpdateProject) Error() string {
+	return fmt.Sprintf("failed to update project (%s): %v\nOutput:\n%s", e.projectDir, e.originalError, e.output)
+}
+
+// ErrorProjectRelocation is an error type that indicates a failure to move a clone of a renamed or transferred project.
+type ErrorProjectRelocation struct {
+	from          string
+	to            string
+	originalError error
+}
+
+func (e *ErrorProjectRelocation) Error() string {
+	return fmt.Sprintf("failed to move project from %s to %s: %v", e.from, e.to, e.originalError)
+}
+
+// ErrorForkDissociation is an error type that indicates a failure to copy the objects a fork borrows into the fork.
+type ErrorForkDissociation struct {
+	path          string
+	originalError error
+	output        []byte
+}
+
+func (e *ErrorForkDissociation) Error() string {
+	return fmt.Sprintf("failed to dissociate fork %s: %v\nOutput:\n%s", e.path, e.originalError, e.output)
+}
+
+// ErrorPruneRepository is an error type that indicates a failure to prune a local repository.
+type ErrorPruneRepository struct {
+	path          string
+	originalError error
+}
+
+func (e *ErrorPruneRepository) Error() string {
+	return fmt.Sprintf("failed to prune repository %s: %v", e.path, e.originalError)
+}
+
+// ErrorStateStore is an error type that indicates a failure to read or write the state file.
+type ErrorStateStore struct {
+	path          string
+	originalError error
+}
+
+func (e *ErrorStateStore) Error() string {
+	return fmt.Sprintf("failed to access state file %s: %v", e.path, e.originalError)
+}
+
+// ErrorJournal is an error type that indicates a failure to read or write the run journal.
+type ErrorJournal struct {
+	path          string
+	originalError error
+}
+
+func (e *ErrorJournal) Error() string {
+	return fmt.Sprintf("failed to access journal %s: %v", e.path, e.originalError)
+}
+
 var (
 	ErrorNoGroupIDs          = errors.New("no group IDs provided")
 	ErrorAllGroupIDsSkipped  = errors.New("all group IDs are skipped")
 	ErrorNoGroupPassed       = errors.New("no group passed")
+	ErrorNoUserPassed        = errors.New("no user passed")
 	ErrorNoConfigPassed      = errors.New("no configuration passed")
 	ErrorNoProjectsPassed    = errors.New("no projects passed")
 	ErrorPathExistsButNotDir = errors.New("path exists but is not a directory")
+	ErrorInterrupted         = errors.New("run interrupted")
+	ErrorNotAdmin            = errors.New("admin mode requires a token of an administrator")
 )
+
+// ErrorInstanceRun is an error type that indicates a failure of the run of an instance of RE_INSTANCES.
+type ErrorInstanceRun struct {
+	instance      string
+	originalError error
+}
+
+func (e *ErrorInstanceRun) Error() string {
+	return fmt.Sprintf("instance %s: %v", e.instance, e.originalError)
+}
+
+func (e *ErrorInstanceRun) Unwrap() error {
+	return e.originalError
+}
+
+// ErrorDiscoveryFailed is an error type that indicates that some groups or projects could not be fetched.
+type ErrorDiscoveryFailed struct {
+	errors uint32
+}
+
+func (e *ErrorDiscoveryFailed) Error() string {
+	return fmt.Sprintf("failed to fetch groups or projects: %d errors", e.errors)
+}
+
+// ErrorFailureThreshold is an error type that indicates that more projects failed than the failure policy allows.
+type ErrorFailureThreshold struct {
+	failed     uint32
+	total      uint32
+	maxPercent int
+}
+
+func (e *ErrorFailureThreshold) Error() string {
+	return fmt.Sprintf("%d of %d projects failed, more than the allowed %d%%", e.failed, e.total, e.maxPercent)
+}
@@ -85,6 +85,70 @@ func TestErrorFailedToCloneProject_Error(t *testing.T) {
 	}
 }
 
+func TestErrorIntegrityCheck_Error(t *testing.T) {
+	err := &ErrorIntegrityCheck{
+		"repo",
+		errors.New("fail"),
+		[]byte("output"),
+	}
+	want := "integrity check failed for project (repo): fail\nOutput:\noutput"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorFailedToUpdateProject_Error(t *testing.T) {
+	err := &ErrorFailedToUpdateProject{
+		"repo",
+		errors.New("fail"),
+		[]byte("output"),
+	}
+	want := "failed to update project (repo): fail\nOutput:\noutput"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorProjectRelocation_Error(t *testing.T) {
+	err := &ErrorProjectRelocation{"old/repo", "new/repo", errors.New("fail")}
+	want := "failed to move project from old/repo to new/repo: fail"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorForkDissociation_Error(t *testing.T) {
+	err := &ErrorForkDissociation{"fork", errors.New("fail"), []byte("out")}
+	want := "failed to dissociate fork fork: fail\nOutput:\nout"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorPruneRepository_Error(t *testing.T) {
+	err := &ErrorPruneRepository{"repo", errors.New("fail")}
+	want := "failed to prune repository repo: fail"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorStateStore_Error(t *testing.T) {
+	err := &ErrorStateStore{"state.json", errors.New("fail")}
+	want := "failed to access state file state.json: fail"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorJournal_Error(t *testing.T) {
+	err := &ErrorJournal{"run.journal", errors.New("fail")}
+	want := "failed to access journal run.journal: fail"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
 func TestErrorVars(t *testing.T) {
 	if ErrorNoGroupIDs.Error() != "no group IDs provided" {
 		t.Error("ErrorNoGroupIDs string mismatch")
@@ -104,4 +168,87 @@ func TestErrorVars(t *testing.T) {
 	if ErrorPathExistsButNotDir.Error() != "path exists but is not a directory" {
 		t.Error("ErrorPathExistsButNotDir string mismatch")
 	}
+	if ErrorInterrupted.Error() != "run interrupted" {
+		t.Error("ErrorInterrupted string mismatch")
+	}
+}
+
+func TestErrorDiscoveryFailed_Error(t *testing.T) {
+	err := &ErrorDiscoveryFailed{2}
+	want := "failed to fetch groups or projects: 2 errors"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorFailureThreshold_Error(t *testing.T) {
+	err := &ErrorFailureThreshold{3, 10, 20}
+	want := "3 of 10 projects failed, more than the allowed 20%"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorAllProjectsFetching_Error(t *testing.T) {
+	err := &ErrorAllProjectsFetching{ErrorNotAdmin}
+	want := "failed to fetch all projects: admin mode requires a token of an administrator"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorUserProjectsFetching_Error(t *testing.T) {
+	err := &ErrorUserProjectsFetching{"alice", errors.New("fail")}
+	want := "failed to fetch projects of user alice: fail"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorInstanceRun_Error(t *testing.T) {
+	err := &ErrorInstanceRun{"internal", &ErrorDiscoveryFailed{2}}
+	want := "instance internal: failed to fetch groups or projects: 2 errors"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorGraphQLGroupNotFound_Error(t *testing.T) {
+	err := &ErrorGraphQLGroupNotFound{"group/sub"}
+	want := "group group/sub not found"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorGraphQLQuery_Error(t *testing.T) {
+	err := &ErrorGraphQLQuery{[]string{"Timeout on Group.projects", "Internal server error"}}
+	want := "GraphQL query failed: Timeout on Group.projects; Internal server error"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorGraphQLID_Error(t *testing.T) {
+	err := &ErrorGraphQLID{"gid://gitlab/Group/abc"}
+	want := "invalid GraphQL ID gid://gitlab/Group/abc"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorProjectFetching_Error(t *testing.T) {
+	err := &ErrorProjectFetching{"group/repo", errors.New("fail")}
+	want := "failed to fetch project group/repo: fail"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorMemberProjectsFetching_Error(t *testing.T) {
+	err := &ErrorMemberProjectsFetching{errors.New("fail")}
+	want := "failed to fetch member projects: fail"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
 }
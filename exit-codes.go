@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+)
+
+// Process exit codes, so schedulers can tell a broken backup from a good one.
+const (
+	// ExitCodeSuccess means every project was processed, or the failures are within RE_MAX_FAILURE_PERCENT.
+	ExitCodeSuccess = 0
+	// ExitCodeFailure means the run failed for a reason without its own code, e.g. the journal could not be opened.
+	ExitCodeFailure = 1
+	// ExitCodeDiscoveryFailure means groups or projects could not be fetched from GitLab, so the backup is incomplete.
+	ExitCodeDiscoveryFailure = 2
+	// ExitCodeConfigError means the configuration is invalid and nothing was processed.
+	ExitCodeConfigError = 3
+	// ExitCodeFailureThreshold means more projects failed than RE_MAX_FAILURE_PERCENT allows.
+	ExitCodeFailureThreshold = 4
+	// ExitCodeInterrupted means the run was stopped by SIGINT or SIGTERM.
+	ExitCodeInterrupted = 130
+)
+
+// exitCodeOf maps the error returned by run to the process exit code.
+func exitCodeOf(err error) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+
+	var invalidValue *config.ErrorInvalidValue
+	var listFileErr *config.ErrorReadListFile
+	var discoveryErr *ErrorDiscoveryFailed
+	var thresholdErr *ErrorFailureThreshold
+
+	switch {
+	case errors.Is(err, ErrorInterrupted):
+		return ExitCodeInterrupted
+	case errors.Is(err, config.ErrorMissingAccessToken), errors.As(err, &invalidValue), errors.As(err, &listFileErr):
+		return ExitCodeConfigError
+	case errors.As(err, &discoveryErr):
+		return ExitCodeDiscoveryFailure
+	case errors.As(err, &thresholdErr):
+		return ExitCodeFailureThreshold
+	default:
+		return ExitCodeFailure
+	}
+}
+
+// checkRunResult applies the failure policy to the outcome of a run.
+// Discovery errors always fail the run, failed projects fail it when their share exceeds the configured percentage.
+func checkRunResult(cfg *config.Config, failed, total, fetchErrors uint32) error {
+	var errs []error
+
+	if fetchErrors > 0 {
+		errs = append(errs, &ErrorDiscoveryFailed{fetchErrors})
+	}
+
+	if failed > 0 && uint64(failed)*100 > uint64(total)*uint64(cfg.GetMaxFailurePercent()) {
+		errs = append(errs, &ErrorFailureThreshold{failed, total, cfg.GetMaxFailurePercent()})
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+)
+
+func TestExitCodeOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"success", nil, ExitCodeSuccess},
+		{"interrupted", ErrorInterrupted, ExitCodeInterrupted},
+		{"missing token", config.ErrorMissingAccessToken, ExitCodeConfigError},
+		{"invalid value", errors.Join(config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+			config.GitlabTokenKey: "token",
+			config.CloneModeKey:   "shallow",
+		})).Validate()), ExitCodeConfigError},
+		{"discovery failure", &ErrorDiscoveryFailed{1}, ExitCodeDiscoveryFailure},
+		{"discovery and project failures", errors.Join(&ErrorDiscoveryFailed{1}, &ErrorFailureThreshold{1, 1, 0}), ExitCodeDiscoveryFailure},
+		{"project failures", &ErrorFailureThreshold{1, 1, 0}, ExitCodeFailureThreshold},
+		{"instance project failures", errors.Join(&ErrorInstanceRun{"public", &ErrorFailureThreshold{1, 1, 0}}), ExitCodeFailureThreshold},
+		{"instance failures", errors.Join(
+			&ErrorInstanceRun{"public", &ErrorFailureThreshold{1, 1, 0}},
+			&ErrorInstanceRun{"internal", &ErrorDiscoveryFailed{1}},
+		), ExitCodeDiscoveryFailure},
+		{"other error", fmt.Errorf("failed to open journal"), ExitCodeFailure},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if code := exitCodeOf(test.err); code != test.expected {
+				t.Errorf("expected exit code %d, got %d", test.expected, code)
+			}
+		})
+	}
+}
+
+func TestCheckRunResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxPercent  string
+		failed      uint32
+		total       uint32
+		fetchErrors uint32
+		expected    error
+	}{
+		{"no failures", "0", 0, 10, 0, nil},
+		{"any failure fails by default", "0", 1, 10, 0, &ErrorFailureThreshold{1, 10, 0}},
+		{"failures within threshold", "10", 1, 10, 0, nil},
+		{"failures over threshold", "10", 2, 10, 0, &ErrorFailureThreshold{2, 10, 10}},
+		{"discovery failure", "100", 10, 10, 1, &ErrorDiscoveryFailed{1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+				config.MaxFailurePercentKey: test.maxPercent,
+			}))
+
+			err := checkRunResult(cfg, test.failed, test.total, test.fetchErrors)
+			if test.expected == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != test.expected.Error() {
+				t.Errorf("expected error '%v', got: '%v'", test.expected, err)
+			}
+		})
+	}
+}
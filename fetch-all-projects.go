@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// fetchAllProjects lists every project of the instance ordered by ID with keyset pagination,
+// which isn't capped and stays fast on any number of projects unlike the offset one.
+// The token must belong to an administrator, otherwise the list would hold every public project visible to the user.
+// Projects in the subtrees of the skipped groups are left out, the query narrows the list down further.
+func fetchAllProjects(ctx context.Context, client DiscoveryService, skipped *skipGroupsResolver, query *projectsQuery) (<-chan *Project, <-chan error) {
+	dataChan := make(chan *Project)
+	errsChan := make(chan error)
+
+	go func() {
+		defer func() {
+			close(dataChan)
+			close(errsChan)
+		}()
+
+		sendErr := func(err error) {
+			select {
+			case <-ctx.Done():
+			case errsChan <- &ErrorAllProjectsFetching{err}:
+			}
+		}
+
+		user, _, err := client.CurrentUser(gitlab.WithContext(ctx))
+		if err != nil {
+			sendErr(err)
+			return
+		}
+		if user == nil || !user.IsAdmin {
+			sendErr(ErrorNotAdmin)
+			return
+		}
+
+		skippedGroups, err := skipped.resolve(ctx)
+		if err != nil {
+			sendErr(err)
+			return
+		}
+
+		opt := &gitlab.ListProjectsOptions{
+			ListOptions: gitlab.ListOptions{
+				Pagination: "keyset",
+				OrderBy:    "id",
+				Sort:       "asc",
+				PerPage:    100,
+			},
+		}
+		query.applyToProjects(opt)
+		options := []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)}
+
+		for {
+			projects, resp, err := client.ListProjects(opt, options...)
+			if err != nil {
+				sendErr(err)
+				return
+			}
+
+			for _, project := range projects {
+				if project == nil || isInSkippedGroups(project, skippedGroups) {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case dataChan <- newProject(project, nil):
+				}
+			}
+
+			if resp.NextLink == "" {
+				break
+			}
+			options = []gitlab.RequestOptionFunc{gitlab.WithContext(ctx), gitlab.WithKeysetPaginationParameters(resp.NextLink)}
+		}
+	}()
+
+	return dataChan, errsChan
+}
+
+// isInSkippedGroups reports whether the project belongs to one of the skipped groups or their subgroups.
+func isInSkippedGroups(project *gitlab.Project, skippedGroups []*Group) bool {
+	if project.Namespace == nil {
+		return false
+	}
+
+	for _, group := range skippedGroups {
+		if isInGroupTree(project.Namespace.FullPath, group.fullPath) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func newAllProjectsTestServer(t *testing.T, isAdmin bool) *Gitlab {
+	var serverURL string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"id": 1, "username": "root", "is_admin": %t}`, isAdmin)
+	})
+	mux.HandleFunc("/api/v4/groups/skipped", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, `{"id": 9, "full_path": "skipped"}`)
+	})
+	mux.HandleFunc("/api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("pagination") != "keyset" || query.Get("order_by") != "id" || query.Get("sort") != "asc" {
+			t.Errorf("expected keyset pagination ordered by id, got %s", r.URL.RawQuery)
+		}
+
+		if query.Get("id_after") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v4/projects?id_after=2&order_by=id&pagination=keyset&per_page=100&sort=asc>; rel="next"`, serverURL))
+			_, _ = fmt.Fprint(w, `[
+				{"id": 1, "path_with_namespace": "root/app", "namespace": {"full_path": "root"}},
+				{"id": 2, "path_with_namespace": "skipped/sub/lib", "namespace": {"full_path": "skipped/sub"}}
+			]`)
+			return
+		}
+
+		_, _ = fmt.Fprint(w, `[{"id": 3, "path_with_namespace": "other/tool", "namespace": {"full_path": "other"}}]`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	serverURL = server.URL
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return NewGitlab(client)
+}
+
+func TestFetchAllProjects(t *testing.T) {
+	client := newAllProjectsTestServer(t, true)
+
+	projectsChan, errsChan := fetchAllProjects(context.Background(), client, newSkipGroupsResolver(client, []string{"skipped"}), &projectsQuery{})
+
+	paths, errs := collectProjects(t, projectsChan, errsChan)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	expected := []string{"other/tool", "root/app"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("expected projects %v, got %v", expected, paths)
+	}
+
+	// The current user, the skipped group and two pages of projects.
+	if client.GetRequests() != 4 {
+		t.Errorf("expected 4 requests, got %d", client.GetRequests())
+	}
+}
+
+func TestFetchAllProjects_NotAdmin(t *testing.T) {
+	client := newAllProjectsTestServer(t, false)
+
+	projectsChan, errsChan := fetchAllProjects(context.Background(), client, newSkipGroupsResolver(client, nil), &projectsQuery{})
+
+	paths, errs := collectProjects(t, projectsChan, errsChan)
+
+	expectedErr := &ErrorAllProjectsFetching{ErrorNotAdmin}
+	if len(errs) != 1 || errs[0].Error() != expectedErr.Error() {
+		t.Fatalf("expected error %v, got %v", expectedErr, errs)
+	}
+	if len(paths) != 0 {
+		t.Errorf("expected no projects, got %v", paths)
+	}
+}
@@ -15,15 +15,15 @@ type Group struct {
 	fullPath string
 }
 
-func fetchGroups(ctx context.Context, client GroupsService, cfg *config.Config) (<-chan *Group, <-chan error) {
+func fetchGroups(ctx context.Context, client GroupsService, cfg *config.Config, skipped *skipGroupsResolver) (<-chan *Group, <-chan error) {
 	if len(cfg.GetGroupIDs()) > 0 {
-		return fetchGroupsByIDs(ctx, client, cfg)
+		return fetchGroupsByIDs(ctx, client, cfg, skipped)
 	}
 
-	return fetchAllGroups(ctx, client, cfg)
+	return fetchAllGroups(ctx, client, cfg, skipped)
 }
 
-func fetchAllGroups(ctx context.Context, client GroupsService, cfg *config.Config) (<-chan *Group, <-chan error) {
+func fetchAllGroups(ctx context.Context, client GroupsService, cfg *config.Config, skipped *skipGroupsResolver) (<-chan *Group, <-chan error) {
 	dataChan := make(chan *Group)
 	errsChan := make(chan error)
 
@@ -33,8 +33,7 @@ func fetchAllGroups(ctx context.Context, client GroupsService, cfg *config.Confi
 			close(errsChan)
 		}()
 
-		skipGroupIDs := cfg.GetSkipGroupIDs()
-		skipGroups, err := fetchSkippedGroupIDs(ctx, client, skipGroupIDs)
+		skipGroups, err := fetchSkippedGroupIDs(ctx, skipped)
 		if err != nil {
 			select {
 			case <-ctx.Done():
@@ -100,7 +99,7 @@ func filterGroupIDs(groupIDs []string, skipGroupIDs []string) []string {
 	return filteredGroupIDs
 }
 
-func fetchGroupsByIDs(ctx context.Context, client GroupsService, cfg *config.Config) (<-chan *Group, <-chan error) {
+func fetchGroupsByIDs(ctx context.Context, client GroupsService, cfg *config.Config, skipped *skipGroupsResolver) (<-chan *Group, <-chan error) {
 	dataChan := make(chan *Group)
 	errsChan := make(chan error)
 
@@ -125,7 +124,7 @@ func fetchGroupsByIDs(ctx context.Context, client GroupsService, cfg *config.Con
 			return
 		}
 
-		skipGroups, err := fetchSkippedGroupIDs(ctx, client, skipGroupIDs)
+		skipGroups, err := fetchSkippedGroupIDs(ctx, skipped)
 		if err != nil {
 			select {
 			case <-ctx.Done():
@@ -134,6 +133,10 @@ func fetchGroupsByIDs(ctx context.Context, client GroupsService, cfg *config.Con
 			return
 		}
 
+		// Selected groups may overlap, e.g. "org" and "org/team". Whoever reaches a group first walks it
+		// with all its subgroups, the others stop there, so every group is passed exactly once.
+		seen := newIDSet()
+
 		semaphore := make(chan struct{}, cfg.GetMaxWorkers())
 		wg := &sync.WaitGroup{}
 
@@ -159,6 +162,10 @@ func fetchGroupsByIDs(ctx context.Context, client GroupsService, cfg *config.Con
 					return
 				}
 
+				if !seen.add(group.id) {
+					return
+				}
+
 				select {
 				case <-ctx.Done():
 				case dataChan <- group:
@@ -179,6 +186,10 @@ func fetchGroupsByIDs(ctx context.Context, client GroupsService, cfg *config.Con
 					}
 
 					for _, subGroup := range groups {
+						if !seen.add(subGroup.id) {
+							continue
+						}
+
 						order = append(order, strconv.Itoa(subGroup.id))
 
 						select {
@@ -197,14 +208,52 @@ func fetchGroupsByIDs(ctx context.Context, client GroupsService, cfg *config.Con
 	return dataChan, errsChan
 }
 
-func fetchSkippedGroupIDs(ctx context.Context, client GroupsService, groupIDs []string) ([]int, error) {
+func fetchSkippedGroupIDs(ctx context.Context, skipped *skipGroupsResolver) ([]int, error) {
+	groups, err := skipped.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	skipGroups := make([]int, 0, len(groups))
+	for _, group := range groups {
+		skipGroups = append(skipGroups, group.id)
+	}
+
+	return skipGroups, nil
+}
+
+// skipGroupsResolver resolves the skipped groups once for the discovery and its project list.
+type skipGroupsResolver struct {
+	client   GroupsService
+	groupIDs []string
+
+	once   sync.Once
+	groups []*Group
+	err    error
+}
+
+func newSkipGroupsResolver(client GroupsService, groupIDs []string) *skipGroupsResolver {
+	return &skipGroupsResolver{client: client, groupIDs: groupIDs}
+}
+
+// resolve returns the skipped groups, they are fetched by the first call only.
+func (r *skipGroupsResolver) resolve(ctx context.Context) ([]*Group, error) {
+	r.once.Do(func() {
+		r.groups, r.err = fetchSkippedGroups(ctx, r.client, r.groupIDs)
+	})
+
+	return r.groups, r.err
+}
+
+// fetchSkippedGroups resolves the skipped group IDs or paths, groups which are not found are ignored.
+func fetchSkippedGroups(ctx context.Context, client GroupsService, groupIDs []string) ([]*Group, error) {
 	if len(groupIDs) == 0 {
-		return []int{}, nil
+		return []*Group{}, nil
 	}
 
 	var fetchErr *ErrorGroupFetching
 
-	skipGroups := make([]int, 0, len(groupIDs))
+	skipGroups := make([]*Group, 0, len(groupIDs))
 	for _, groupID := range groupIDs {
 		group, err := fetchGroupByID(ctx, client, groupID)
 
@@ -214,7 +263,7 @@ func fetchSkippedGroupIDs(ctx context.Context, client GroupsService, groupIDs []
 		}
 
 		if group != nil {
-			skipGroups = append(skipGroups, group.id)
+			skipGroups = append(skipGroups, group)
 		}
 	}
 
@@ -24,6 +24,9 @@ type FakeGitlabGroups struct {
 	fetchErr    error
 	fetchAllErr error
 	fetchSubErr error
+
+	mu        sync.Mutex
+	getCounts map[string]int
 }
 
 func NewFakeGitlab(groups map[string]*gitlab.Group, sleeps ...time.Duration) *FakeGitlabGroups {
@@ -43,6 +46,13 @@ func (f *FakeGitlabGroups) GetGroup(gid string, _ *gitlab.GetGroupOptions, _ ...
 		time.Sleep(f.sleep)
 	}
 
+	f.mu.Lock()
+	if f.getCounts == nil {
+		f.getCounts = map[string]int{}
+	}
+	f.getCounts[gid]++
+	f.mu.Unlock()
+
 	if f.fetchErr != nil {
 		return nil, nil, f.fetchErr
 	}
@@ -321,7 +331,7 @@ func TestFetchSkippedGroupIDs(t *testing.T) {
 			}
 
 			ctx := context.Background()
-			skippedGroups, err := fetchSkippedGroupIDs(ctx, client, test.groupIDs)
+			skippedGroups, err := fetchSkippedGroupIDs(ctx, newSkipGroupsResolver(client, test.groupIDs))
 			if err != nil {
 				var fetchErr *ErrorGroupFetching
 				if errors.As(err, &fetchErr) && err.Error() == test.expectedErr.Error() {
@@ -439,7 +449,7 @@ func TestFetchGroupsByIDs(t *testing.T) {
 				filteredGroupIDs := filterGroupIDs(groupIDs, skipGroupIDs)
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg)
+				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				groups := map[string]struct{}{}
 
@@ -491,7 +501,7 @@ func TestFetchGroupsByIDs(t *testing.T) {
 				}
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg)
+				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				dataDone := false
 				errsDone := false
@@ -538,7 +548,7 @@ func TestFetchGroupsByIDs(t *testing.T) {
 				client := NewFakeGitlab(gitlabGroups)
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg)
+				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				select {
 				case err := <-errsChan:
@@ -566,7 +576,7 @@ func TestFetchGroupsByIDs(t *testing.T) {
 				client := NewFakeGitlab(gitlabGroups)
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg)
+				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				select {
 				case err := <-errsChan:
@@ -604,7 +614,7 @@ func TestFetchGroupsByIDs(t *testing.T) {
 				go func() {
 					defer wg.Done()
 
-					dataChan, errsChan = fetchGroupsByIDs(ctx, client, cfg)
+					dataChan, errsChan = fetchGroupsByIDs(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 				}()
 				wg.Wait()
 
@@ -637,7 +647,7 @@ func TestFetchGroupsByIDs(t *testing.T) {
 				client := NewFakeGitlab(gitlabGroups)
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg)
+				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				select {
 				case err := <-errsChan:
@@ -665,7 +675,7 @@ func TestFetchGroupsByIDs(t *testing.T) {
 				client := NewFakeGitlab(gitlabGroups)
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg)
+				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				select {
 				case err := <-errsChan:
@@ -698,7 +708,7 @@ func TestFetchGroupsByIDs(t *testing.T) {
 				}
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg)
+				dataChan, errsChan := fetchGroupsByIDs(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				dataDone := false
 				errsDone := false
@@ -776,7 +786,7 @@ func TestFetchAllGroups(t *testing.T) {
 				client := NewFakeGitlab(gitlabGroups)
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchAllGroups(ctx, client, cfg)
+				dataChan, errsChan := fetchAllGroups(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				groups := map[string]struct{}{}
 
@@ -812,7 +822,7 @@ func TestFetchAllGroups(t *testing.T) {
 				client.nextPage = 1 // simulate pagination
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchAllGroups(ctx, client, cfg)
+				dataChan, errsChan := fetchAllGroups(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				groups := map[string]int{}
 
@@ -864,7 +874,7 @@ func TestFetchAllGroups(t *testing.T) {
 				expectedErr := &ErrorGroupsFetching{errors.New("fetching error")}
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchAllGroups(ctx, client, cfg)
+				dataChan, errsChan := fetchAllGroups(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				select {
 				case err := <-errsChan:
@@ -892,7 +902,7 @@ func TestFetchAllGroups(t *testing.T) {
 				skipGroupIDs := cfg.GetSkipGroupIDs()
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchAllGroups(ctx, client, cfg)
+				dataChan, errsChan := fetchAllGroups(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				groups := map[string]struct{}{}
 
@@ -944,7 +954,7 @@ func TestFetchAllGroups(t *testing.T) {
 				skipGroupIDs := cfg.GetSkipGroupIDs()
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchAllGroups(ctx, client, cfg)
+				dataChan, errsChan := fetchAllGroups(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				groups := map[string]struct{}{}
 
@@ -997,7 +1007,7 @@ func TestFetchAllGroups(t *testing.T) {
 				expectedErr := &ErrorGroupFetching{"example_group20", client.fetchErr}
 
 				ctx := context.Background()
-				dataChan, errsChan := fetchAllGroups(ctx, client, cfg)
+				dataChan, errsChan := fetchAllGroups(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 
 				groups := map[string]struct{}{}
 
@@ -1051,7 +1061,7 @@ func TestFetchAllGroups(t *testing.T) {
 				go func() {
 					defer wg.Done()
 
-					dataChan, errsChan = fetchAllGroups(ctx, client, cfg)
+					dataChan, errsChan = fetchAllGroups(ctx, client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
 				}()
 				wg.Wait()
 
@@ -1139,7 +1149,7 @@ func TestFetchGroups(t *testing.T) {
 			client := NewFakeGitlab(gitlabGroups)
 			ctx := context.Background()
 
-			dataChan, errsChan := fetchGroups(ctx, client, test.cfg)
+			dataChan, errsChan := fetchGroups(ctx, client, test.cfg, newSkipGroupsResolver(client, test.cfg.GetSkipGroupIDs()))
 
 			groups := map[string]struct{}{}
 			dataDone := false
@@ -1177,3 +1187,38 @@ func TestFetchGroups(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchGroupsByIDs_Overlapping(t *testing.T) {
+	cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+		config.GroupIDsKey: "org, org/team, org/team/sub, org",
+	}))
+
+	client := NewFakeGitlab(map[string]*gitlab.Group{
+		"org":          {ID: 1, FullPath: "org"},
+		"org/team":     {ID: 2, FullPath: "org/team"},
+		"org/team/sub": {ID: 3, FullPath: "org/team/sub"},
+	})
+	client.subGroups = map[string][]*gitlab.Group{
+		"1": {{ID: 2, FullPath: "org/team"}, {ID: 4, FullPath: "org/other"}},
+		"2": {{ID: 3, FullPath: "org/team/sub"}},
+	}
+
+	dataChan, errsChan := fetchGroupsByIDs(context.Background(), client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
+
+	go func() {
+		for err := range errsChan {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}()
+
+	var groups []string
+	for group := range dataChan {
+		groups = append(groups, group.fullPath)
+	}
+	slices.Sort(groups)
+
+	expected := []string{"org", "org/other", "org/team", "org/team/sub"}
+	if !slices.Equal(groups, expected) {
+		t.Errorf("expected every group once %v, got %v", expected, groups)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// fetchMemberProjects lists every project the token is a member of, independent of the permissions on their groups.
+// A positive minAccessLevel limits the projects to those where the member has at least this access level,
+// the query narrows the list down further.
+func fetchMemberProjects(ctx context.Context, client ProjectsService, minAccessLevel int, query *projectsQuery) (<-chan *Project, <-chan error) {
+	dataChan := make(chan *Project)
+	errsChan := make(chan error)
+
+	go func() {
+		defer func() {
+			close(dataChan)
+			close(errsChan)
+		}()
+
+		membership := true
+		opt := &gitlab.ListProjectsOptions{
+			Membership: &membership,
+		}
+		if minAccessLevel > 0 {
+			accessLevel := gitlab.AccessLevelValue(minAccessLevel)
+			opt.MinAccessLevel = &accessLevel
+		}
+		query.applyToProjects(opt)
+		opt.PerPage = 100
+
+		for {
+			projects, resp, err := client.ListProjects(opt, gitlab.WithContext(ctx))
+			if err != nil {
+				select {
+				case <-ctx.Done():
+				case errsChan <- &ErrorMemberProjectsFetching{err}:
+				}
+				return
+			}
+
+			for _, project := range projects {
+				if project == nil {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case dataChan <- newProject(project, nil):
+				}
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			opt.Page = resp.NextPage
+		}
+	}()
+
+	return dataChan, errsChan
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestFetchMemberProjects(t *testing.T) {
+	tests := []struct {
+		name           string
+		minAccessLevel int
+		activeSince    time.Time
+		client         *FakeGitlabProjects
+		expected       []string
+		expectedErr    error
+	}{
+		{
+			name: "any access level",
+			client: &FakeGitlabProjects{
+				member: []*gitlab.Project{{ID: 1, PathWithNamespace: "group/member"}, nil},
+			},
+			expected: []string{"group/member"},
+		},
+		{
+			name:           "minimum access level",
+			minAccessLevel: 30,
+			client: &FakeGitlabProjects{
+				member: []*gitlab.Project{{ID: 1, PathWithNamespace: "group/member"}},
+			},
+			expected: []string{"group/member"},
+		},
+		{
+			name:        "active since",
+			activeSince: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			client: &FakeGitlabProjects{
+				member: []*gitlab.Project{{ID: 1, PathWithNamespace: "group/member"}},
+			},
+			expected: []string{"group/member"},
+		},
+		{
+			name: "fetching error",
+			client: &FakeGitlabProjects{
+				fetchErr: errors.New("forbidden"),
+			},
+			expectedErr: &ErrorMemberProjectsFetching{errors.New("forbidden")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			projectsChan, errsChan := fetchMemberProjects(context.Background(), test.client, test.minAccessLevel, &projectsQuery{activeSince: test.activeSince})
+			paths, errs := collectProjects(t, projectsChan, errsChan)
+
+			if !slices.Equal(paths, test.expected) {
+				t.Errorf("expected projects %v, got %v", test.expected, paths)
+			}
+
+			if test.expectedErr != nil {
+				if len(errs) != 1 || errs[0].Error() != test.expectedErr.Error() {
+					t.Errorf("expected error %v, got %v", test.expectedErr, errs)
+				}
+				return
+			}
+
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			opt := test.client.listOptions
+			if opt.Membership == nil || !*opt.Membership {
+				t.Errorf("expected membership filter, got %+v", opt)
+			}
+
+			if test.minAccessLevel == 0 && opt.MinAccessLevel != nil {
+				t.Errorf("expected no access level filter, got %v", *opt.MinAccessLevel)
+			}
+
+			if test.minAccessLevel > 0 && (opt.MinAccessLevel == nil || int(*opt.MinAccessLevel) != test.minAccessLevel) {
+				t.Errorf("expected access level %d, got %v", test.minAccessLevel, opt.MinAccessLevel)
+			}
+
+			if test.activeSince.IsZero() != (opt.LastActivityAfter == nil) ||
+				(opt.LastActivityAfter != nil && !opt.LastActivityAfter.Equal(test.activeSince)) {
+				t.Errorf("expected last activity after %s, got %v", test.activeSince, opt.LastActivityAfter)
+			}
+		})
+	}
+}
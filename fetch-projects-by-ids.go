@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// fetchProjectsByIDs resolves the projects by their numeric IDs or namespace/project paths, bypassing group traversal.
+func fetchProjectsByIDs(ctx context.Context, client ProjectsService, projectIDs []string) (<-chan *Project, <-chan error) {
+	dataChan := make(chan *Project)
+	errsChan := make(chan error)
+
+	go func() {
+		defer func() {
+			close(dataChan)
+			close(errsChan)
+		}()
+
+		for _, projectID := range projectIDs {
+			project, err := fetchProjectByID(ctx, client, projectID)
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case errsChan <- err:
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case dataChan <- project:
+			}
+		}
+	}()
+
+	return dataChan, errsChan
+}
+
+func fetchProjectByID(ctx context.Context, client ProjectsService, projectID string) (*Project, error) {
+	var pid any = projectID
+	if id, err := strconv.Atoi(projectID); err == nil {
+		pid = id
+	}
+
+	project, _, err := client.GetProject(pid, &gitlab.GetProjectOptions{}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, &ErrorProjectFetching{projectID, err}
+	}
+
+	if project == nil {
+		return nil, &ErrorProjectFetching{projectID, ErrorNoProjectsPassed}
+	}
+
+	return newProject(project, nil), nil
+}
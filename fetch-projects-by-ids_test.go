@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestFetchProjectsByIDs(t *testing.T) {
+	client := &FakeGitlabProjects{
+		byIDs: map[string]*gitlab.Project{
+			"42":            {ID: 42, PathWithNamespace: "group/by-id"},
+			"group/by-path": {ID: 43, PathWithNamespace: "group/by-path"},
+		},
+	}
+
+	projectsChan, errsChan := fetchProjectsByIDs(context.Background(), client, []string{"42", "missing/project", "group/by-path"})
+	paths, errs := collectProjects(t, projectsChan, errsChan)
+
+	expected := []string{"group/by-id", "group/by-path"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("expected projects %v, got %v", expected, paths)
+	}
+
+	expectedErr := &ErrorProjectFetching{"missing/project", errors.New("404 Project Not Found")}
+	if len(errs) != 1 || errs[0].Error() != expectedErr.Error() {
+		t.Errorf("expected error %v, got %v", expectedErr, errs)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"time"
 
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
@@ -12,10 +13,62 @@ type Project struct {
 	httpURLToRepo     string
 	path              string
 	pathWithNamespace string
+	lastActivityAt    *time.Time
 	group             *Group
+	// shared is set for a project of another namespace which is shared with the group.
+	shared     bool
+	archived   bool
+	visibility string
+	topics     []string
+	// repositorySize is the size of the repository in bytes, nil when statistics weren't fetched.
+	repositorySize *int64
+	// forkedFromID and forkedFromPath identify the upstream project of a fork.
+	forkedFromID   int
+	forkedFromPath string
+	// upstream is the upstream project of a fork when it is cloned in the same run, see orderForks.
+	upstream *Project
+	// synced is closed by proceedProjects when the project is synced, forks wait for it to reference the clone.
+	synced chan struct{}
 }
 
-func fetchProjectByGroup(ctx context.Context, client ProjectsService, group *Group) (<-chan *Project, <-chan error) {
+// newProject converts a project of the API. Projects are listed without the simple option,
+// as the simple representation lacks the archived state, the visibility and the upstream project of forks.
+func newProject(project *gitlab.Project, group *Group) *Project {
+	prepared := &Project{
+		id:                project.ID,
+		path:              project.Path,
+		pathWithNamespace: project.PathWithNamespace,
+		sshURLToRepo:      project.SSHURLToRepo,
+		httpURLToRepo:     project.HTTPURLToRepo,
+		lastActivityAt:    project.LastActivityAt,
+		group:             group,
+		archived:          project.Archived,
+		visibility:        string(project.Visibility),
+		topics:            project.Topics,
+	}
+
+	if project.Statistics != nil {
+		size := project.Statistics.RepositorySize
+		prepared.repositorySize = &size
+	}
+
+	if project.ForkedFromProject != nil {
+		prepared.forkedFromID = project.ForkedFromProject.ID
+		prepared.forkedFromPath = project.ForkedFromProject.PathWithNamespace
+	}
+
+	return prepared
+}
+
+// fetchProjectByGroup lists the projects of the group, withShared adds the projects shared with the group,
+// the query narrows the list down.
+func fetchProjectByGroup(
+	ctx context.Context,
+	client ProjectsService,
+	group *Group,
+	withShared bool,
+	query *projectsQuery,
+) (<-chan *Project, <-chan error) {
 	dataChan := make(chan *Project)
 	errsChan := make(chan error)
 
@@ -34,13 +87,11 @@ func fetchProjectByGroup(ctx context.Context, client ProjectsService, group *Gro
 		}
 
 		subGroups := false
-		withShared := false
-		simple := true
 		opt := &gitlab.ListGroupProjectsOptions{
 			IncludeSubGroups: &subGroups,
 			WithShared:       &withShared,
-			Simple:           &simple,
 		}
+		query.applyToGroupProjects(opt)
 		opt.PerPage = 100
 
 		for {
@@ -58,19 +109,13 @@ func fetchProjectByGroup(ctx context.Context, client ProjectsService, group *Gro
 					continue
 				}
 
-				prepare := &Project{
-					id:                project.ID,
-					path:              project.Path,
-					pathWithNamespace: project.PathWithNamespace,
-					sshURLToRepo:      project.SSHURLToRepo,
-					httpURLToRepo:     project.HTTPURLToRepo,
-					group:             group,
-				}
+				prepared := newProject(project, group)
+				prepared.shared = project.Namespace != nil && project.Namespace.FullPath != group.fullPath
 
 				select {
 				case <-ctx.Done():
 					return
-				case dataChan <- prepare:
+				case dataChan <- prepared:
 				}
 			}
 
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"maps"
 	"slices"
 	"sync"
@@ -13,9 +14,66 @@ import (
 )
 
 type FakeGitlabProjects struct {
-	nextPage int
-	projects map[int]map[int]*gitlab.Project
-	fetchErr error
+	nextPage     int
+	projects     map[int]map[int]*gitlab.Project
+	userProjects map[string][]*gitlab.Project
+	byIDs        map[string]*gitlab.Project
+	member       []*gitlab.Project
+	shared       map[int][]*gitlab.Project
+	listOptions  *gitlab.ListProjectsOptions
+	fetchErr     error
+}
+
+func (f *FakeGitlabProjects) ListProjects(opt *gitlab.ListProjectsOptions, _ ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error) {
+	f.listOptions = opt
+
+	if f.fetchErr != nil {
+		return nil, nil, f.fetchErr
+	}
+
+	nextPage := f.nextPage
+	if opt.Page == nextPage {
+		nextPage = 0
+	}
+
+	return f.member, &gitlab.Response{
+		NextPage: nextPage,
+	}, nil
+}
+
+func (f *FakeGitlabProjects) GetProject(pid any, _ *gitlab.GetProjectOptions, _ ...gitlab.RequestOptionFunc) (*gitlab.Project, *gitlab.Response, error) {
+	if f.fetchErr != nil {
+		return nil, nil, f.fetchErr
+	}
+
+	project, exists := f.byIDs[fmt.Sprint(pid)]
+	if !exists {
+		return nil, nil, errors.New("404 Project Not Found")
+	}
+
+	return project, nil, nil
+}
+
+func (f *FakeGitlabProjects) ListUserProjects(uid any, opt *gitlab.ListProjectsOptions, _ ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error) {
+	f.listOptions = opt
+
+	if f.fetchErr != nil {
+		return nil, nil, f.fetchErr
+	}
+
+	projects, exists := f.userProjects[fmt.Sprint(uid)]
+	if !exists {
+		return nil, nil, errors.New("user not found")
+	}
+
+	nextPage := f.nextPage
+	if opt.Page == nextPage {
+		nextPage = 0
+	}
+
+	return projects, &gitlab.Response{
+		NextPage: nextPage,
+	}, nil
 }
 
 func (f *FakeGitlabProjects) ListGroupProjects(gid int, opt *gitlab.ListGroupProjectsOptions, _ ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error) {
@@ -29,7 +87,11 @@ func (f *FakeGitlabProjects) ListGroupProjects(gid int, opt *gitlab.ListGroupPro
 		if opt.Page == nextPage {
 			nextPage = 0
 		}
-		return slices.Collect(maps.Values(projects)), &gitlab.Response{
+		result := slices.Collect(maps.Values(projects))
+		if opt.WithShared != nil && *opt.WithShared {
+			result = append(result, f.shared[gid]...)
+		}
+		return result, &gitlab.Response{
 			NextPage: nextPage,
 		}, nil
 	}
@@ -59,7 +121,7 @@ func TestFetchProjects(t *testing.T) {
 		{
 			name: "Passed nil group",
 			fn: func(t *testing.T) (<-chan *Project, <-chan error) {
-				dataChan, errsChan := fetchProjectByGroup(context.Background(), &FakeGitlabProjects{}, nil)
+				dataChan, errsChan := fetchProjectByGroup(context.Background(), &FakeGitlabProjects{}, nil, false, &projectsQuery{})
 
 				select {
 				case err := <-errsChan:
@@ -80,7 +142,7 @@ func TestFetchProjects(t *testing.T) {
 				fetchErr := errors.New("fetch error")
 				dataChan, errsChan := fetchProjectByGroup(context.Background(), &FakeGitlabProjects{
 					fetchErr: fetchErr,
-				}, group)
+				}, group, false, &projectsQuery{})
 
 				expectedErr := &ErrorProjectsFetching{group.id, fetchErr}
 
@@ -115,7 +177,7 @@ func TestFetchProjects(t *testing.T) {
 
 					dataChan, errsChan = fetchProjectByGroup(ctx, &FakeGitlabProjects{
 						projects: projects,
-					}, &Group{id: 1})
+					}, &Group{id: 1}, false, &projectsQuery{})
 				}()
 				wg.Wait()
 
@@ -143,7 +205,7 @@ func TestFetchProjects(t *testing.T) {
 				projects := getFakeProjects()
 				dataChan, errsChan := fetchProjectByGroup(context.Background(), &FakeGitlabProjects{
 					projects: projects,
-				}, &Group{id: 1})
+				}, &Group{id: 1}, false, &projectsQuery{})
 
 				received := map[int]struct{}{}
 
@@ -189,7 +251,7 @@ func TestFetchProjects(t *testing.T) {
 				dataChan, errsChan := fetchProjectByGroup(context.Background(), &FakeGitlabProjects{
 					projects: projects,
 					nextPage: 1,
-				}, &Group{id: 1})
+				}, &Group{id: 1}, false, &projectsQuery{})
 
 				received := map[int]int{}
 
@@ -239,7 +301,7 @@ func TestFetchProjects(t *testing.T) {
 				projects[1][nilProjectId] = nil // Simulate a nil project
 				dataChan, errsChan := fetchProjectByGroup(context.Background(), &FakeGitlabProjects{
 					projects: projects,
-				}, &Group{id: 1})
+				}, &Group{id: 1}, false, &projectsQuery{})
 
 				received := map[int]struct{}{}
 
@@ -302,3 +364,42 @@ func TestFetchProjects(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchProjects_Shared(t *testing.T) {
+	group := &Group{id: 1, fullPath: "root"}
+	client := &FakeGitlabProjects{
+		projects: map[int]map[int]*gitlab.Project{
+			1: {10: {ID: 10, PathWithNamespace: "root/app", Namespace: &gitlab.ProjectNamespace{FullPath: "root"}}},
+		},
+		shared: map[int][]*gitlab.Project{
+			1: {{ID: 20, PathWithNamespace: "other/lib", Namespace: &gitlab.ProjectNamespace{FullPath: "other"}}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		withShared bool
+		expected   map[int]bool
+	}{
+		{"without shared projects", false, map[int]bool{10: false}},
+		{"with shared projects", true, map[int]bool{10: false, 20: true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dataChan, errsChan := fetchProjectByGroup(context.Background(), client, group, test.withShared, &projectsQuery{})
+
+			received := map[int]bool{}
+			for project := range dataChan {
+				received[project.id] = project.shared
+			}
+			for err := range errsChan {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !maps.Equal(received, test.expected) {
+				t.Errorf("expected projects %v, got %v", test.expected, received)
+			}
+		})
+	}
+}
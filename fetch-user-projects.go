@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// fetchUserProjects lists the personal projects of the users, config.CurrentUserAlias is resolved to the owner of the token.
+// The query narrows the lists down.
+func fetchUserProjects(
+	ctx context.Context,
+	client ProjectsService,
+	users UsersService,
+	userIDs []string,
+	query *projectsQuery,
+) (<-chan *Project, <-chan error) {
+	dataChan := make(chan *Project)
+	errsChan := make(chan error)
+
+	go func() {
+		defer func() {
+			close(dataChan)
+			close(errsChan)
+		}()
+
+		for _, userID := range userIDs {
+			uid, err := resolveUserID(ctx, users, userID)
+			if err == nil {
+				err = fetchProjectsOfUser(ctx, client, uid, query, dataChan)
+			}
+
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case errsChan <- &ErrorUserProjectsFetching{userID, err}:
+				}
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+
+	return dataChan, errsChan
+}
+
+// resolveUserID returns the numeric ID or the username accepted by the users API.
+func resolveUserID(ctx context.Context, users UsersService, userID string) (any, error) {
+	if userID != config.CurrentUserAlias {
+		if id, err := strconv.Atoi(userID); err == nil {
+			return id, nil
+		}
+
+		return userID, nil
+	}
+
+	user, _, err := users.CurrentUser(gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, ErrorNoUserPassed
+	}
+
+	return user.ID, nil
+}
+
+func fetchProjectsOfUser(ctx context.Context, client ProjectsService, uid any, query *projectsQuery, dataChan chan<- *Project) error {
+	opt := &gitlab.ListProjectsOptions{}
+	query.applyToProjects(opt)
+	opt.PerPage = 100
+
+	for {
+		projects, resp, err := client.ListUserProjects(uid, opt, gitlab.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+
+		for _, project := range projects {
+			if project == nil {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case dataChan <- newProject(project, nil):
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+type FakeGitlabUsers struct {
+	currentUser *gitlab.User
+	fetchErr    error
+}
+
+func (f *FakeGitlabUsers) CurrentUser(_ ...gitlab.RequestOptionFunc) (*gitlab.User, *gitlab.Response, error) {
+	if f.fetchErr != nil {
+		return nil, nil, f.fetchErr
+	}
+
+	return f.currentUser, nil, nil
+}
+
+// FakeGitlabDiscovery combines the fake services into a DiscoveryService.
+type FakeGitlabDiscovery struct {
+	*FakeGitlabGroups
+	*FakeGitlabProjects
+	*FakeGitlabUsers
+}
+
+func collectProjects(t *testing.T, projectsChan <-chan *Project, errsChan <-chan error) ([]string, []error) {
+	t.Helper()
+
+	var paths []string
+	var errs []error
+
+	for projectsChan != nil || errsChan != nil {
+		select {
+		case project, ok := <-projectsChan:
+			if !ok {
+				projectsChan = nil
+				continue
+			}
+			paths = append(paths, project.pathWithNamespace)
+		case err, ok := <-errsChan:
+			if !ok {
+				errsChan = nil
+				continue
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	slices.Sort(paths)
+
+	return paths, errs
+}
+
+func TestFetchUserProjects(t *testing.T) {
+	projects := &FakeGitlabProjects{
+		userProjects: map[string][]*gitlab.Project{
+			"7":     {{ID: 1, PathWithNamespace: "me/prototype"}},
+			"alice": {{ID: 2, PathWithNamespace: "alice/tool"}, nil},
+			"42":    {{ID: 3, PathWithNamespace: "bob/notes"}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		userIDs        []string
+		users          *FakeGitlabUsers
+		expected       []string
+		expectedErrors []string
+	}{
+		{
+			name:     "usernames and IDs",
+			userIDs:  []string{"alice", "42"},
+			users:    &FakeGitlabUsers{},
+			expected: []string{"alice/tool", "bob/notes"},
+		},
+		{
+			name:     "current user",
+			userIDs:  []string{config.CurrentUserAlias},
+			users:    &FakeGitlabUsers{currentUser: &gitlab.User{ID: 7}},
+			expected: []string{"me/prototype"},
+		},
+		{
+			name:           "failed users do not stop others",
+			userIDs:        []string{config.CurrentUserAlias, "unknown", "alice"},
+			users:          &FakeGitlabUsers{fetchErr: errors.New("unauthorized")},
+			expected:       []string{"alice/tool"},
+			expectedErrors: []string{"failed to fetch projects of user @me: unauthorized", "failed to fetch projects of user unknown: user not found"},
+		},
+		{
+			name:           "no current user",
+			userIDs:        []string{config.CurrentUserAlias, "alice"},
+			users:          &FakeGitlabUsers{},
+			expected:       []string{"alice/tool"},
+			expectedErrors: []string{"failed to fetch projects of user @me: no user passed"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			projectsChan, errsChan := fetchUserProjects(context.Background(), projects, test.users, test.userIDs, &projectsQuery{})
+			paths, errs := collectProjects(t, projectsChan, errsChan)
+
+			if !slices.Equal(paths, test.expected) {
+				t.Errorf("expected projects %v, got %v", test.expected, paths)
+			}
+
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			if !slices.Equal(messages, test.expectedErrors) {
+				t.Errorf("expected errors %v, got %v", test.expectedErrors, messages)
+			}
+		})
+	}
+}
+
+func TestFetchUserProjects_ActiveSince(t *testing.T) {
+	projects := &FakeGitlabProjects{
+		userProjects: map[string][]*gitlab.Project{"alice": {{ID: 2, PathWithNamespace: "alice/tool"}}},
+	}
+	activeSince := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	projectsChan, errsChan := fetchUserProjects(context.Background(), projects, &FakeGitlabUsers{}, []string{"alice"},
+		&projectsQuery{activeSince: activeSince})
+	if _, errs := collectProjects(t, projectsChan, errsChan); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	opt := projects.listOptions
+	if opt.LastActivityAfter == nil || !opt.LastActivityAfter.Equal(activeSince) {
+		t.Errorf("expected last activity after %s, got %v", activeSince, opt.LastActivityAfter)
+	}
+}
+
+func TestFetchUserProjects_VisibilityAndTopic(t *testing.T) {
+	projects := &FakeGitlabProjects{
+		userProjects: map[string][]*gitlab.Project{"alice": {{ID: 2, PathWithNamespace: "alice/tool"}}},
+	}
+	query := newProjectsQuery(config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+		"RE_INCLUDE_VISIBILITY": "private",
+		"RE_INCLUDE_TOPICS":     "backup",
+	})))
+
+	projectsChan, errsChan := fetchUserProjects(context.Background(), projects, &FakeGitlabUsers{}, []string{"alice"}, query)
+	if _, errs := collectProjects(t, projectsChan, errsChan); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	opt := projects.listOptions
+	if opt.Visibility == nil || *opt.Visibility != gitlab.PrivateVisibility {
+		t.Errorf("expected private visibility, got %v", opt.Visibility)
+	}
+	if opt.Topic == nil || *opt.Topic != "backup" {
+		t.Errorf("expected topic backup, got %v", opt.Topic)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+)
+
+// excludedProject is a discovered project which is not cloned and the rule which excluded it.
+type excludedProject struct {
+	project *Project
+	reason  string
+}
+
+// projectFilter decides which discovered projects are cloned.
+type projectFilter struct {
+	includePaths      []*config.PathPattern
+	excludePaths      []*config.PathPattern
+	includeVisibility []config.Visibility
+	excludeVisibility []config.Visibility
+	includeTopics     []string
+	excludeTopics     []string
+	archived          config.ArchivedPolicy
+	forks             config.ForksMode
+	activeSince       time.Time
+	maxRepoSize       int64
+}
+
+func newProjectFilter(cfg *config.Config) *projectFilter {
+	return &projectFilter{
+		includePaths:      cfg.GetIncludePaths(),
+		excludePaths:      cfg.GetExcludePaths(),
+		includeVisibility: cfg.GetIncludeVisibility(),
+		excludeVisibility: cfg.GetExcludeVisibility(),
+		includeTopics:     cfg.GetIncludeTopics(),
+		excludeTopics:     cfg.GetExcludeTopics(),
+		archived:          cfg.GetArchived(),
+		forks:             cfg.GetForks(),
+		activeSince:       cfg.GetActiveSince(),
+		maxRepoSize:       cfg.GetMaxRepoSize(),
+	}
+}
+
+// exclusionReason returns the rule which excludes the project, empty when the project is cloned.
+// Exclude lists take precedence over include ones. Projects without a known last activity are never
+// excluded as inactive, GitLab filters the lists of all and member projects by the activity itself.
+func (f *projectFilter) exclusionReason(project *Project) string {
+	switch {
+	case f.archived == config.ArchivedPolicySkip && project.archived:
+		return fmt.Sprintf("archived, %s=%s", config.ArchivedKey, f.archived)
+	case f.archived == config.ArchivedPolicyOnly && !project.archived:
+		return fmt.Sprintf("not archived, %s=%s", config.ArchivedKey, f.archived)
+	case f.forks == config.ForksSkip && project.forkedFromID != 0:
+		return fmt.Sprintf("fork of %s, %s=%s", project.forkedFromPath, config.ForksKey, f.forks)
+	case project.lastActivityAt != nil && project.lastActivityAt.Before(f.activeSince):
+		return fmt.Sprintf("last active %s, before %s %s",
+			project.lastActivityAt.Format(time.DateOnly), config.ActiveSinceKey, f.activeSince.Format(time.DateOnly))
+	case f.maxRepoSize > 0 && project.repositorySize != nil && *project.repositorySize > f.maxRepoSize:
+		return fmt.Sprintf("size %s, above %s %s",
+			formatSize(*project.repositorySize), config.MaxRepoSizeKey, formatSize(f.maxRepoSize))
+	}
+
+	visibility := config.Visibility(project.visibility)
+	if slices.Contains(f.excludeVisibility, visibility) {
+		return fmt.Sprintf("%s %s", config.ExcludeVisibilityKey, visibility)
+	}
+	if len(f.includeVisibility) > 0 && !slices.Contains(f.includeVisibility, visibility) {
+		return fmt.Sprintf("visibility %s, no match in %s", visibility, config.IncludeVisibilityKey)
+	}
+
+	for _, topic := range f.excludeTopics {
+		if hasTopic(project, topic) {
+			return fmt.Sprintf("%s %s", config.ExcludeTopicsKey, topic)
+		}
+	}
+	if len(f.includeTopics) > 0 && !slices.ContainsFunc(f.includeTopics, func(topic string) bool {
+		return hasTopic(project, topic)
+	}) {
+		return fmt.Sprintf("no topic in %s", config.IncludeTopicsKey)
+	}
+
+	for _, pattern := range f.excludePaths {
+		if pattern.Match(project.pathWithNamespace) {
+			return fmt.Sprintf("%s %s", config.ExcludePathsKey, pattern)
+		}
+	}
+
+	if len(f.includePaths) == 0 {
+		return ""
+	}
+
+	for _, pattern := range f.includePaths {
+		if pattern.Match(project.pathWithNamespace) {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("no match in %s", config.IncludePathsKey)
+}
+
+// hasTopic reports whether the project has the topic, GitLab topics are case-insensitive.
+func hasTopic(project *Project, topic string) bool {
+	return slices.ContainsFunc(project.topics, func(projectTopic string) bool {
+		return strings.EqualFold(projectTopic, topic)
+	})
+}
+
+// selectProjects filters the discovered projects and queues them for cloning: their sizes are fetched when needed,
+// they are ordered by size and forks come after their upstream projects. The excluded projects are sent to
+// the second channel, both channels must be drained by the caller.
+func selectProjects(
+	ctx context.Context,
+	cfg *config.Config,
+	client ProjectsService,
+	discoveredChan <-chan *Project,
+) (<-chan *Project, <-chan *excludedProject) {
+	filter := newProjectFilter(cfg)
+
+	if cfg.GetStatistics() {
+		discoveredChan = fetchProjectSizes(ctx, client, filter, cfg.GetMaxWorkers(), discoveredChan)
+	}
+
+	projectsChan, excludedChan := filterProjects(ctx, filter, discoveredChan)
+
+	if cfg.GetStatistics() {
+		projectsChan = queueBySize(ctx, cfg.GetCloneOrder(), projectsChan)
+	}
+
+	if cfg.GetForks() == config.ForksReference {
+		projectsChan = orderForks(ctx, projectsChan)
+	}
+
+	return projectsChan, excludedChan
+}
+
+// filterProjects passes the projects the filter keeps and sends the other ones with their reasons
+// to the second channel, both channels must be drained by the caller.
+func filterProjects(ctx context.Context, filter *projectFilter, projectsChan <-chan *Project) (<-chan *Project, <-chan *excludedProject) {
+	dataChan := make(chan *Project)
+	excludedChan := make(chan *excludedProject)
+
+	go func() {
+		defer func() {
+			close(dataChan)
+			close(excludedChan)
+		}()
+
+		for project := range projectsChan {
+			if project == nil {
+				continue
+			}
+
+			if reason := filter.exclusionReason(project); reason != "" {
+				select {
+				case <-ctx.Done():
+					return
+				case excludedChan <- &excludedProject{project, reason}:
+				}
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case dataChan <- project:
+			}
+		}
+	}()
+
+	return dataChan, excludedChan
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+)
+
+func TestProjectFilter_ExclusionReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		envs     map[string]string
+		path     string
+		archived bool
+		fork     bool
+		// lastActivity is a date, empty when the last activity is unknown.
+		lastActivity string
+		size         *int64
+		visibility   string
+		topics       []string
+		expected     string
+	}{
+		{
+			name: "no patterns",
+			path: "org/app",
+		},
+		{
+			name:     "excluded",
+			envs:     map[string]string{config.ExcludePathsKey: "**/sandbox-*"},
+			path:     "org/team/sandbox-alice",
+			expected: "RE_EXCLUDE_PATHS **/sandbox-*",
+		},
+		{
+			name: "included",
+			envs: map[string]string{config.IncludePathsKey: "org/** re:^tools/"},
+			path: "tools/cli",
+		},
+		{
+			name:     "not included",
+			envs:     map[string]string{config.IncludePathsKey: "org/**"},
+			path:     "other/app",
+			expected: "no match in RE_INCLUDE_PATHS",
+		},
+		{
+			name: "exclude takes precedence",
+			envs: map[string]string{
+				config.IncludePathsKey: "org/**",
+				config.ExcludePathsKey: "org/legacy/**",
+			},
+			path:     "org/legacy/app",
+			expected: "RE_EXCLUDE_PATHS org/legacy/**",
+		},
+		{
+			name:     "archived included by default",
+			path:     "org/app",
+			archived: true,
+		},
+		{
+			name:     "archived skipped",
+			envs:     map[string]string{config.ArchivedKey: "skip"},
+			path:     "org/app",
+			archived: true,
+			expected: "archived, RE_ARCHIVED=skip",
+		},
+		{
+			name:     "active skipped",
+			envs:     map[string]string{config.ArchivedKey: "only"},
+			path:     "org/app",
+			expected: "not archived, RE_ARCHIVED=only",
+		},
+		{
+			name: "fork cloned by default",
+			path: "alice/app",
+			fork: true,
+		},
+		{
+			name:     "fork skipped",
+			envs:     map[string]string{config.ForksKey: "skip"},
+			path:     "alice/app",
+			fork:     true,
+			expected: "fork of org/app, RE_FORKS=skip",
+		},
+		{
+			name:         "active",
+			envs:         map[string]string{config.ActiveSinceKey: "2026-01-01"},
+			path:         "org/app",
+			lastActivity: "2026-03-01",
+		},
+		{
+			name:         "inactive",
+			envs:         map[string]string{config.ActiveSinceKey: "2026-01-01"},
+			path:         "org/app",
+			lastActivity: "2019-05-20",
+			expected:     "last active 2019-05-20, before RE_ACTIVE_SINCE 2026-01-01",
+		},
+		{
+			name: "small repository",
+			envs: map[string]string{config.MaxRepoSizeKey: "1MiB"},
+			path: "org/app",
+			size: sizeOf(1 << 10),
+		},
+		{
+			name:     "large repository",
+			envs:     map[string]string{config.MaxRepoSizeKey: "1MiB"},
+			path:     "org/app",
+			size:     sizeOf(3 << 30),
+			expected: "size 3.0 GiB, above RE_MAX_REPO_SIZE 1.0 MiB",
+		},
+		{
+			name: "unknown size",
+			envs: map[string]string{config.MaxRepoSizeKey: "1MiB"},
+			path: "org/app",
+		},
+		{
+			name:       "included visibility",
+			envs:       map[string]string{config.IncludeVisibilityKey: "private,internal"},
+			path:       "org/app",
+			visibility: "internal",
+		},
+		{
+			name:       "not included visibility",
+			envs:       map[string]string{config.IncludeVisibilityKey: "private,internal"},
+			path:       "org/app",
+			visibility: "public",
+			expected:   "visibility public, no match in RE_INCLUDE_VISIBILITY",
+		},
+		{
+			name:       "excluded visibility",
+			envs:       map[string]string{config.ExcludeVisibilityKey: "public"},
+			path:       "org/app",
+			visibility: "public",
+			expected:   "RE_EXCLUDE_VISIBILITY public",
+		},
+		{
+			name:   "included topic",
+			envs:   map[string]string{config.IncludeTopicsKey: "pci, production"},
+			path:   "org/app",
+			topics: []string{"Production", "go"},
+		},
+		{
+			name:     "not included topic",
+			envs:     map[string]string{config.IncludeTopicsKey: "pci, production"},
+			path:     "org/app",
+			topics:   []string{"go"},
+			expected: "no topic in RE_INCLUDE_TOPICS",
+		},
+		{
+			name: "excluded topic takes precedence",
+			envs: map[string]string{
+				config.IncludeTopicsKey: "pci",
+				config.ExcludeTopicsKey: "deprecated",
+			},
+			path:     "org/app",
+			topics:   []string{"pci", "deprecated"},
+			expected: "RE_EXCLUDE_TOPICS deprecated",
+		},
+		{
+			name: "unknown activity",
+			envs: map[string]string{config.ActiveSinceKey: "2026-01-01"},
+			path: "org/app",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter := newProjectFilter(config.NewConfig(config.NewMemoryEnvLoader(test.envs)))
+
+			project := &Project{
+				pathWithNamespace: test.path,
+				archived:          test.archived,
+				repositorySize:    test.size,
+				visibility:        test.visibility,
+				topics:            test.topics,
+			}
+			if test.fork {
+				project.forkedFromID, project.forkedFromPath = 1, "org/app"
+			}
+			if test.lastActivity != "" {
+				lastActivityAt, err := time.Parse(time.DateOnly, test.lastActivity)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				project.lastActivityAt = &lastActivityAt
+			}
+
+			if reason := filter.exclusionReason(project); reason != test.expected {
+				t.Errorf("expected reason %q, got %q", test.expected, reason)
+			}
+		})
+	}
+}
+
+func TestFilterProjects(t *testing.T) {
+	filter := newProjectFilter(config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+		config.ExcludePathsKey: "org/legacy/**",
+	})))
+
+	projectsChan := make(chan *Project)
+	go func() {
+		defer close(projectsChan)
+
+		for _, path := range []string{"org/app", "org/legacy/app", "org/lib"} {
+			projectsChan <- &Project{pathWithNamespace: path}
+		}
+	}()
+
+	dataChan, excludedChan := filterProjects(context.Background(), filter, projectsChan)
+
+	var kept, excluded []string
+	for dataChan != nil || excludedChan != nil {
+		select {
+		case project, ok := <-dataChan:
+			if !ok {
+				dataChan = nil
+				continue
+			}
+			kept = append(kept, project.pathWithNamespace)
+		case project, ok := <-excludedChan:
+			if !ok {
+				excludedChan = nil
+				continue
+			}
+			excluded = append(excluded, project.project.pathWithNamespace)
+		}
+	}
+
+	if expected := []string{"org/app", "org/lib"}; !slices.Equal(kept, expected) {
+		t.Errorf("expected projects %v, got %v", expected, kept)
+	}
+	if expected := []string{"org/legacy/app"}; !slices.Equal(excluded, expected) {
+		t.Errorf("expected excluded projects %v, got %v", expected, excluded)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+)
+
+// orderForks passes the projects which are not forks as they come and holds the forks back until all projects
+// are discovered. A fork whose upstream project is part of the run is linked to it and passed after it,
+// so the clone of the upstream can be referenced, forks of forks come after their own upstreams.
+// Forks of projects outside the run are cloned in full.
+func orderForks(ctx context.Context, projectsChan <-chan *Project) <-chan *Project {
+	dataChan := make(chan *Project)
+
+	go func() {
+		defer close(dataChan)
+
+		send := func(project *Project) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case dataChan <- project:
+				return true
+			}
+		}
+
+		discovered := map[int]*Project{}
+		var forks []*Project
+
+		for project := range projectsChan {
+			if project == nil {
+				continue
+			}
+
+			project.synced = make(chan struct{})
+			discovered[project.id] = project
+
+			if project.forkedFromID != 0 {
+				forks = append(forks, project)
+				continue
+			}
+
+			if !send(project) {
+				return
+			}
+		}
+
+		for _, fork := range forks {
+			fork.upstream = discovered[fork.forkedFromID]
+		}
+
+		depths := map[*Project]int{}
+		for _, fork := range forks {
+			// The depth is bounded by the number of forks in case of a cycle in broken data.
+			depth := 0
+			for upstream := fork.upstream; upstream != nil && depth <= len(forks); upstream = upstream.upstream {
+				depth++
+			}
+			depths[fork] = depth
+		}
+
+		slices.SortStableFunc(forks, func(a, b *Project) int {
+			return depths[a] - depths[b]
+		})
+
+		for _, fork := range forks {
+			if !send(fork) {
+				return
+			}
+		}
+	}()
+
+	return dataChan
+}
+
+// waitForUpstream blocks until the upstream project of the fork is synced, it reports false when the context is done.
+func waitForUpstream(ctx context.Context, project *Project) bool {
+	if project.upstream == nil || project.upstream.synced == nil {
+		return true
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-project.upstream.synced:
+		return true
+	}
+}
+
+// markSynced releases the forks waiting for the project.
+func markSynced(project *Project) {
+	if project.synced != nil {
+		close(project.synced)
+	}
+}
+
+// alternatesFiles list the object directories a working copy or a bare repository borrows objects from.
+var alternatesFiles = []string{
+	filepath.Join(".git", "objects", "info", "alternates"),
+	filepath.Join("objects", "info", "alternates"),
+}
+
+// readAlternates returns the alternates file of the repository and the absolute object directories it lists,
+// an empty path when the repository doesn't borrow objects.
+func readAlternates(repository string) (string, []string) {
+	for _, name := range alternatesFiles {
+		path := filepath.Join(repository, name)
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var dirs []string
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			// Relative paths are relative to the object directory of the repository.
+			if !filepath.IsAbs(line) {
+				line = filepath.Join(filepath.Dir(filepath.Dir(path)), line)
+			}
+			if abs, err := filepath.Abs(line); err == nil {
+				dirs = append(dirs, abs)
+			}
+		}
+
+		return path, dirs
+	}
+
+	return "", nil
+}
+
+// findForkReferences returns the local repositories which borrow objects with the object directories they borrow from,
+// the output, archived output and quarantine directories are searched.
+func findForkReferences(cfg *config.Config) (map[string][]string, error) {
+	root := cfg.GetOutputDir()
+	if root == "" {
+		root = "."
+	}
+
+	references := map[string][]string{}
+	var errs []error
+
+	for _, dir := range []string{root, cfg.GetArchivedOutputDir(), cfg.GetQuarantineDir()} {
+		if dir == "" {
+			continue
+		}
+
+		repositories, err := findLocalRepositories(dir, cfg.GetStagingDirs()...)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, repository := range repositories {
+			if _, objectDirs := readAlternates(repository); len(objectDirs) > 0 {
+				references[repository] = objectDirs
+			}
+		}
+	}
+
+	return references, errors.Join(errs...)
+}
+
+// dissociateForks copies the objects the forks borrow from the repository into the forks and removes their
+// alternates, so the repository can be moved or removed. Dissociated forks are removed from the references.
+func dissociateForks(ctx context.Context, osWrapper OSWrapper, references map[string][]string, repository string) error {
+	dir, err := filepath.Abs(repository)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for fork, objectDirs := range references {
+		if fork == filepath.Clean(repository) || !slices.ContainsFunc(objectDirs, func(objectDir string) bool { return isInDir(objectDir, dir) }) {
+			continue
+		}
+
+		if output, err := osWrapper.ExecuteCommand(ctx, "git", "-C", fork, "repack", "-a", "-d"); err != nil {
+			errs = append(errs, &ErrorForkDissociation{fork, err, output})
+			continue
+		}
+
+		if alternates, _ := readAlternates(fork); alternates != "" {
+			if err := osWrapper.RemoveAll(alternates); err != nil {
+				errs = append(errs, &ErrorForkDissociation{fork, err, nil})
+				continue
+			}
+		}
+
+		delete(references, fork)
+	}
+
+	return errors.Join(errs...)
+}
+
+// isInDir reports whether the absolute path is the directory or inside it.
+func isInDir(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+)
+
+func TestOrderForks(t *testing.T) {
+	projectsChan := make(chan *Project)
+	go func() {
+		defer close(projectsChan)
+
+		projectsChan <- &Project{id: 4, pathWithNamespace: "bob/app", forkedFromID: 3}
+		projectsChan <- &Project{id: 3, pathWithNamespace: "alice/app", forkedFromID: 1}
+		projectsChan <- &Project{id: 5, pathWithNamespace: "carol/lib", forkedFromID: 100}
+		projectsChan <- &Project{id: 1, pathWithNamespace: "org/app"}
+		projectsChan <- &Project{id: 2, pathWithNamespace: "org/lib"}
+	}()
+
+	var order []string
+	upstreams := map[string]string{}
+	for project := range orderForks(context.Background(), projectsChan) {
+		order = append(order, project.pathWithNamespace)
+		if project.upstream != nil {
+			upstreams[project.pathWithNamespace] = project.upstream.pathWithNamespace
+		}
+		if project.synced == nil {
+			t.Errorf("expected synced channel of %s", project.pathWithNamespace)
+		}
+	}
+
+	expectedOrder := []string{"org/app", "org/lib", "carol/lib", "alice/app", "bob/app"}
+	if !slices.Equal(order, expectedOrder) {
+		t.Errorf("expected order %v, got %v", expectedOrder, order)
+	}
+
+	expectedUpstreams := map[string]string{"alice/app": "org/app", "bob/app": "alice/app"}
+	if len(upstreams) != len(expectedUpstreams) {
+		t.Errorf("expected upstreams %v, got %v", expectedUpstreams, upstreams)
+	}
+	for fork, upstream := range expectedUpstreams {
+		if upstreams[fork] != upstream {
+			t.Errorf("expected upstream of %s to be %s, got %q", fork, upstream, upstreams[fork])
+		}
+	}
+}
+
+func TestWaitForUpstream(t *testing.T) {
+	upstream := &Project{synced: make(chan struct{})}
+	fork := &Project{upstream: upstream}
+
+	if !waitForUpstream(context.Background(), &Project{}) {
+		t.Error("expected a project without upstream not to wait")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if waitForUpstream(ctx, fork) {
+		t.Error("expected waiting to stop with the context")
+	}
+
+	markSynced(upstream)
+	if !waitForUpstream(context.Background(), fork) {
+		t.Error("expected a synced upstream not to block")
+	}
+}
+
+func TestDissociateForks_UpstreamMovedAndPruned(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	source := filepath.Join(root, "source")
+	git := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+
+	git("init", "--quiet", source)
+	if err := os.WriteFile(filepath.Join(source, "README.md"), []byte("app\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	git("-C", source, "add", "README.md")
+	git("-C", source, "commit", "--quiet", "-m", "initial")
+
+	cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+		config.OutputDirKey: filepath.Join(root, "out"),
+		config.PruneModeKey: "quarantine",
+	}))
+	upstream := &Project{id: 1, httpURLToRepo: source, pathWithNamespace: "org/app"}
+	fork := &Project{id: 2, httpURLToRepo: source, pathWithNamespace: "alice/app", forkedFromID: 1, upstream: upstream}
+	other := &Project{id: 3, httpURLToRepo: source, pathWithNamespace: "bob/app", forkedFromID: 1, upstream: upstream}
+
+	cloner := NewGitCloner(GetDefaultOSWrapper())
+	for _, project := range []*Project{upstream, fork, other} {
+		if _, err := cloner.cloneProject(context.Background(), cfg, project); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	forkDir := getProjectDir(cfg, fork)
+	if alternates, _ := readAlternates(forkDir); alternates == "" {
+		t.Fatal("expected the fork to borrow the objects of the upstream clone")
+	}
+
+	// The upstream is renamed on GitLab, its clone is moved.
+	store := NewStateStore(filepath.Join(root, "state.json"))
+	store.Record(upstream, getProjectDir(cfg, upstream), SyncStatusCloned, nil, nil)
+	upstream.pathWithNamespace = "org/renamed"
+
+	movedFrom, err := relocateProject(context.Background(), cfg, GetDefaultOSWrapper(), store, upstream, getProjectDir(cfg, upstream))
+	if err != nil || movedFrom == "" {
+		t.Fatalf("expected the upstream clone to be moved, got %q, %v", movedFrom, err)
+	}
+
+	if alternates, _ := readAlternates(forkDir); alternates != "" {
+		t.Errorf("expected the fork to be dissociated, got %s", alternates)
+	}
+	git("-C", forkDir, "fsck", "--full")
+
+	// The other fork borrows from the moved clone now and is dissociated before it is pruned.
+	otherDir := getProjectDir(cfg, other)
+	if err := os.WriteFile(filepath.Join(otherDir, "objects", "info", "alternates"),
+		[]byte(filepath.Join(getProjectDir(cfg, upstream), "objects")+"\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pruned, err := pruneRepositories(context.Background(), GetDefaultOSWrapper(), cfg, []string{getProjectDir(cfg, upstream)}, time.Now())
+	if err != nil || len(pruned) != 1 {
+		t.Fatalf("expected the upstream clone to be pruned, got %v, %v", pruned, err)
+	}
+
+	git("-C", otherDir, "fsck", "--full")
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// graphQLPageSize is the number of nodes requested per page, the maximum GitLab allows.
+const graphQLPageSize = 100
+
+// GraphQLGitlab lists subgroups and projects of groups with the GraphQL API and uses the REST API for everything else.
+// The descendants of a group are fetched with a single paginated query and cached, so walking a group tree
+// subgroup by subgroup costs one query per tree instead of one request per group.
+// Every call follows the cursor pagination to the end and returns all items at once without a next page.
+type GraphQLGitlab struct {
+	*Gitlab
+
+	mu sync.Mutex
+	// paths maps group IDs to full paths, GraphQL looks groups up by full path only.
+	paths map[int]string
+	// children maps group IDs of fetched group trees to their direct subgroups.
+	children map[int][]*gitlab.Group
+	// restProjects passes all project lists to the REST API, e.g. when details missing in GraphQL are needed.
+	restProjects bool
+}
+
+func NewGraphQLGitlab(rest *Gitlab) *GraphQLGitlab {
+	return &GraphQLGitlab{
+		Gitlab:   rest,
+		paths:    map[int]string{},
+		children: map[int][]*gitlab.Group{},
+	}
+}
+
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphQLGroup struct {
+	ID       string `json:"id"`
+	FullPath string `json:"fullPath"`
+	Parent   *struct {
+		ID string `json:"id"`
+	} `json:"parent"`
+}
+
+type graphQLProject struct {
+	ID             string     `json:"id"`
+	Path           string     `json:"path"`
+	FullPath       string     `json:"fullPath"`
+	SSHURLToRepo   string     `json:"sshUrlToRepo"`
+	HTTPURLToRepo  string     `json:"httpUrlToRepo"`
+	LastActivityAt *time.Time `json:"lastActivityAt"`
+	Archived       bool       `json:"archived"`
+	Visibility     string     `json:"visibility"`
+	Topics         []string   `json:"topics"`
+	Namespace      *struct {
+		FullPath string `json:"fullPath"`
+	} `json:"namespace"`
+}
+
+const descendantGroupsQuery = `query {
+  group(fullPath: %s) {
+    id
+    fullPath
+    descendantGroups(first: %d, after: %s) {
+      nodes { id fullPath parent { id } }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+const groupProjectsQuery = `query {
+  group(fullPath: %s) {
+    projects(includeSubgroups: %t, first: %d, after: %s) {
+      nodes { id path fullPath sshUrlToRepo httpUrlToRepo lastActivityAt archived visibility topics namespace { fullPath } }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+// GetGroup fetches the group with the REST API and remembers its full path.
+func (g *GraphQLGitlab) GetGroup(gid string, opt *gitlab.GetGroupOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Group, *gitlab.Response, error) {
+	group, resp, err := g.Gitlab.GetGroup(gid, opt, options...)
+	if err == nil && group != nil {
+		g.mu.Lock()
+		g.paths[group.ID] = group.FullPath
+		g.mu.Unlock()
+	}
+
+	return group, resp, err
+}
+
+// ListSubGroups returns the direct subgroups of the group except the skipped ones, like the REST API does.
+func (g *GraphQLGitlab) ListSubGroups(gid string, opt *gitlab.ListSubGroupsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Group, *gitlab.Response, error) {
+	id, fullPath, err := g.resolveGroup(gid, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g.mu.Lock()
+	children, ok := g.children[id]
+	g.mu.Unlock()
+
+	if !ok {
+		if err := g.fetchDescendantGroups(id, fullPath, options...); err != nil {
+			return nil, nil, err
+		}
+
+		g.mu.Lock()
+		children = g.children[id]
+		g.mu.Unlock()
+	}
+
+	var skipGroups []int
+	if opt != nil && opt.SkipGroups != nil {
+		skipGroups = *opt.SkipGroups
+	}
+
+	result := make([]*gitlab.Group, 0, len(children))
+	for _, child := range children {
+		if !slices.Contains(skipGroups, child.ID) {
+			result = append(result, child)
+		}
+	}
+
+	return result, &gitlab.Response{}, nil
+}
+
+// ListGroupProjects returns the projects of the group, optionally with its subgroups.
+// GraphQL doesn't list projects shared with a group, such requests are passed to the REST API.
+func (g *GraphQLGitlab) ListGroupProjects(gid int, opt *gitlab.ListGroupProjectsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error) {
+	if g.restProjects || (opt != nil && opt.WithShared != nil && *opt.WithShared) {
+		return g.Gitlab.ListGroupProjects(gid, opt, options...)
+	}
+
+	_, fullPath, err := g.resolveGroup(strconv.Itoa(gid), options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	includeSubGroups := opt != nil && opt.IncludeSubGroups != nil && *opt.IncludeSubGroups
+
+	var result []*gitlab.Project
+	cursor := ""
+
+	for {
+		var response struct {
+			Data struct {
+				Group *struct {
+					Projects struct {
+						Nodes    []*graphQLProject `json:"nodes"`
+						PageInfo graphQLPageInfo   `json:"pageInfo"`
+					} `json:"projects"`
+				} `json:"group"`
+			} `json:"data"`
+		}
+
+		query := fmt.Sprintf(groupProjectsQuery, graphQLString(fullPath), includeSubGroups, graphQLPageSize, graphQLCursor(cursor))
+		if err := g.doGraphQL(query, &response, options...); err != nil {
+			return nil, nil, err
+		}
+
+		group := response.Data.Group
+		if group == nil {
+			return nil, nil, &ErrorGraphQLGroupNotFound{fullPath}
+		}
+
+		for _, node := range group.Projects.Nodes {
+			if node == nil {
+				continue
+			}
+
+			project, err := node.toProject()
+			if err != nil {
+				return nil, nil, err
+			}
+			result = append(result, project)
+		}
+
+		if !group.Projects.PageInfo.HasNextPage {
+			break
+		}
+		cursor = group.Projects.PageInfo.EndCursor
+	}
+
+	return result, &gitlab.Response{}, nil
+}
+
+// fetchDescendantGroups caches the full paths and direct subgroups of every group in the tree of the group.
+func (g *GraphQLGitlab) fetchDescendantGroups(id int, fullPath string, options ...gitlab.RequestOptionFunc) error {
+	children := map[int][]*gitlab.Group{id: {}}
+	paths := map[int]string{id: fullPath}
+
+	cursor := ""
+
+	for {
+		var response struct {
+			Data struct {
+				Group *struct {
+					DescendantGroups struct {
+						Nodes    []*graphQLGroup `json:"nodes"`
+						PageInfo graphQLPageInfo `json:"pageInfo"`
+					} `json:"descendantGroups"`
+				} `json:"group"`
+			} `json:"data"`
+		}
+
+		query := fmt.Sprintf(descendantGroupsQuery, graphQLString(fullPath), graphQLPageSize, graphQLCursor(cursor))
+		if err := g.doGraphQL(query, &response, options...); err != nil {
+			return err
+		}
+
+		group := response.Data.Group
+		if group == nil {
+			return &ErrorGraphQLGroupNotFound{fullPath}
+		}
+
+		for _, node := range group.DescendantGroups.Nodes {
+			if node == nil || node.Parent == nil {
+				continue
+			}
+
+			groupID, err := parseGraphQLID(node.ID)
+			if err != nil {
+				return err
+			}
+			parentID, err := parseGraphQLID(node.Parent.ID)
+			if err != nil {
+				return err
+			}
+
+			paths[groupID] = node.FullPath
+			if _, ok := children[groupID]; !ok {
+				children[groupID] = []*gitlab.Group{}
+			}
+			children[parentID] = append(children[parentID], &gitlab.Group{
+				ID:       groupID,
+				FullPath: node.FullPath,
+				ParentID: parentID,
+			})
+		}
+
+		if !group.DescendantGroups.PageInfo.HasNextPage {
+			break
+		}
+		cursor = group.DescendantGroups.PageInfo.EndCursor
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for groupID, subGroups := range children {
+		g.children[groupID] = subGroups
+	}
+	for groupID, groupPath := range paths {
+		g.paths[groupID] = groupPath
+	}
+
+	return nil
+}
+
+// resolveGroup returns the ID and the full path of a group passed by ID or full path.
+func (g *GraphQLGitlab) resolveGroup(gid string, options ...gitlab.RequestOptionFunc) (int, string, error) {
+	id, err := strconv.Atoi(gid)
+	if err == nil {
+		g.mu.Lock()
+		fullPath, ok := g.paths[id]
+		g.mu.Unlock()
+
+		if ok {
+			return id, fullPath, nil
+		}
+	}
+
+	group, _, err := g.GetGroup(gid, &gitlab.GetGroupOptions{}, options...)
+	if err != nil {
+		return 0, "", err
+	}
+	if group == nil {
+		return 0, "", ErrorNoGroupPassed
+	}
+
+	return group.ID, group.FullPath, nil
+}
+
+// doGraphQL runs the query and decodes its data into the response. GraphQL reports errors with HTTP 200
+// and partial data, where a failed field is null, so any error of the response fails the query.
+func (g *GraphQLGitlab) doGraphQL(query string, response any, options ...gitlab.RequestOptionFunc) error {
+	g.requests.Add(1)
+
+	var body json.RawMessage
+	if _, err := g.client.GraphQL.Do(gitlab.GraphQLQuery{Query: query}, &body, options...); err != nil {
+		return err
+	}
+
+	var result struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return err
+	}
+
+	if len(result.Errors) > 0 {
+		messages := make([]string, 0, len(result.Errors))
+		for _, graphQLErr := range result.Errors {
+			messages = append(messages, graphQLErr.Message)
+		}
+		return &ErrorGraphQLQuery{messages}
+	}
+
+	return json.Unmarshal(body, response)
+}
+
+func (p *graphQLProject) toProject() (*gitlab.Project, error) {
+	id, err := parseGraphQLID(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &gitlab.Project{
+		ID:                id,
+		Path:              p.Path,
+		PathWithNamespace: p.FullPath,
+		SSHURLToRepo:      p.SSHURLToRepo,
+		HTTPURLToRepo:     p.HTTPURLToRepo,
+		LastActivityAt:    p.LastActivityAt,
+		Archived:          p.Archived,
+		Visibility:        gitlab.VisibilityValue(p.Visibility),
+		Topics:            p.Topics,
+	}
+	if p.Namespace != nil {
+		project.Namespace = &gitlab.ProjectNamespace{FullPath: p.Namespace.FullPath}
+	}
+
+	return project, nil
+}
+
+// parseGraphQLID returns the numeric ID of a GraphQL global ID like "gid://gitlab/Group/42".
+func parseGraphQLID(globalID string) (int, error) {
+	id, err := strconv.Atoi(globalID[strings.LastIndex(globalID, "/")+1:])
+	if err != nil {
+		return 0, &ErrorGraphQLID{globalID}
+	}
+
+	return id, nil
+}
+
+// graphQLString quotes the value as a GraphQL string literal.
+func graphQLString(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
+
+// graphQLCursor returns the cursor argument of the next page, null for the first one.
+func graphQLCursor(cursor string) string {
+	if cursor == "" {
+		return "null"
+	}
+
+	return graphQLString(cursor)
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+const graphQLDescendantsPage1 = `{"data": {"group": {"id": "gid://gitlab/Group/1", "fullPath": "root", "descendantGroups": {
+	"nodes": [
+		{"id": "gid://gitlab/Group/2", "fullPath": "root/sub", "parent": {"id": "gid://gitlab/Group/1"}},
+		{"id": "gid://gitlab/Group/4", "fullPath": "root/skipped", "parent": {"id": "gid://gitlab/Group/1"}}
+	],
+	"pageInfo": {"hasNextPage": true, "endCursor": "groups-2"}
+}}}}`
+
+const graphQLDescendantsPage2 = `{"data": {"group": {"id": "gid://gitlab/Group/1", "fullPath": "root", "descendantGroups": {
+	"nodes": [
+		{"id": "gid://gitlab/Group/3", "fullPath": "root/sub/deep", "parent": {"id": "gid://gitlab/Group/2"}}
+	],
+	"pageInfo": {"hasNextPage": false, "endCursor": ""}
+}}}}`
+
+const graphQLProjectsPage1 = `{"data": {"group": {"projects": {
+	"nodes": [
+		{"id": "gid://gitlab/Project/10", "path": "app", "fullPath": "root/app", "httpUrlToRepo": "https://gitlab.example.com/root/app.git",
+		 "lastActivityAt": "2026-01-02T03:04:05Z", "visibility": "private", "topics": ["pci"], "namespace": {"fullPath": "root"}}
+	],
+	"pageInfo": {"hasNextPage": true, "endCursor": "projects-2"}
+}}}}`
+
+const graphQLProjectsPage2 = `{"data": {"group": {"projects": {
+	"nodes": [
+		{"id": "gid://gitlab/Project/20", "path": "lib", "fullPath": "root/sub/lib", "sshUrlToRepo": "git@gitlab.example.com:root/sub/lib.git",
+		 "namespace": {"fullPath": "root/sub"}}
+	],
+	"pageInfo": {"hasNextPage": false, "endCursor": ""}
+}}}}`
+
+// newGraphQLTestServer serves a group tree root > root/sub > root/sub/deep with root/skipped and two projects,
+// every list is split into two pages.
+func newGraphQLTestServer(t *testing.T) (*GraphQLGitlab, *[]string) {
+	var requests []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/groups/", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, "rest "+r.URL.Path)
+
+		switch strings.TrimPrefix(r.URL.Path, "/api/v4/groups/") {
+		case "1", "root":
+			_, _ = fmt.Fprint(w, `{"id": 1, "full_path": "root"}`)
+		case "1/projects":
+			_, _ = fmt.Fprint(w, `[{"id": 30, "path_with_namespace": "other/shared", "namespace": {"full_path": "other"}}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"message": "404 Group Not Found"}`)
+		}
+	})
+	mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var body gitlab.GraphQLQuery
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("unexpected request body: %v", err)
+		}
+
+		nextPage := !strings.Contains(body.Query, "after: null")
+
+		switch {
+		case strings.Contains(body.Query, `"missing"`):
+			requests = append(requests, "graphql missing")
+			_, _ = fmt.Fprint(w, `{"data": {"group": null}}`)
+		case strings.Contains(body.Query, `"failing"`):
+			requests = append(requests, "graphql failing")
+			_, _ = fmt.Fprint(w, `{"data": {"group": {"descendantGroups": null, "projects": null}},
+				"errors": [{"message": "Timeout on Group.projects"}]}`)
+		case strings.Contains(body.Query, `"nulls"`):
+			requests = append(requests, "graphql nulls")
+			_, _ = fmt.Fprint(w, `{"data": {"group": {"projects": {
+				"nodes": [null, {"id": "gid://gitlab/Project/40", "path": "app", "fullPath": "nulls/app"}],
+				"pageInfo": {"hasNextPage": false, "endCursor": ""}
+			}}}}`)
+		case strings.Contains(body.Query, "descendantGroups"):
+			requests = append(requests, fmt.Sprintf("graphql groups %t", nextPage))
+			if nextPage {
+				_, _ = fmt.Fprint(w, graphQLDescendantsPage2)
+			} else {
+				_, _ = fmt.Fprint(w, graphQLDescendantsPage1)
+			}
+		case strings.Contains(body.Query, "projects(includeSubgroups: true"):
+			requests = append(requests, fmt.Sprintf("graphql projects %t", nextPage))
+			if nextPage {
+				_, _ = fmt.Fprint(w, graphQLProjectsPage2)
+			} else {
+				_, _ = fmt.Fprint(w, graphQLProjectsPage1)
+			}
+		default:
+			t.Errorf("unexpected query: %s", body.Query)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return NewGraphQLGitlab(NewGitlab(client)), &requests
+}
+
+func TestGraphQLGitlab_ListSubGroups(t *testing.T) {
+	client, requests := newGraphQLTestServer(t)
+
+	skipGroups := []int{4}
+	listSubGroups := func(gid string) []string {
+		groups, resp, err := client.ListSubGroups(gid, &gitlab.ListSubGroupsOptions{SkipGroups: &skipGroups})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.NextPage != 0 {
+			t.Errorf("expected no next page, got %d", resp.NextPage)
+		}
+
+		var paths []string
+		for _, group := range groups {
+			paths = append(paths, fmt.Sprintf("%d:%s", group.ID, group.FullPath))
+		}
+		return paths
+	}
+
+	if result := listSubGroups("1"); !slices.Equal(result, []string{"2:root/sub"}) {
+		t.Errorf("unexpected subgroups of root: %v", result)
+	}
+	if result := listSubGroups("2"); !slices.Equal(result, []string{"3:root/sub/deep"}) {
+		t.Errorf("unexpected subgroups of root/sub: %v", result)
+	}
+	if result := listSubGroups("3"); len(result) != 0 {
+		t.Errorf("unexpected subgroups of root/sub/deep: %v", result)
+	}
+
+	expected := []string{"rest /api/v4/groups/1", "graphql groups false", "graphql groups true"}
+	if !slices.Equal(*requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, *requests)
+	}
+	if client.GetRequests() != int64(len(expected)) {
+		t.Errorf("expected %d counted requests, got %d", len(expected), client.GetRequests())
+	}
+}
+
+func TestGraphQLGitlab_ListGroupProjects(t *testing.T) {
+	client, requests := newGraphQLTestServer(t)
+
+	includeSubGroups := true
+	projects, resp, err := client.ListGroupProjects(1, &gitlab.ListGroupProjectsOptions{IncludeSubGroups: &includeSubGroups})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.NextPage != 0 {
+		t.Errorf("expected no next page, got %d", resp.NextPage)
+	}
+
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %d", len(projects))
+	}
+
+	app, lib := projects[0], projects[1]
+	if app.ID != 10 || app.PathWithNamespace != "root/app" || app.Namespace.FullPath != "root" ||
+		app.HTTPURLToRepo != "https://gitlab.example.com/root/app.git" || app.LastActivityAt == nil ||
+		app.Visibility != gitlab.PrivateVisibility || !slices.Equal(app.Topics, []string{"pci"}) {
+		t.Errorf("unexpected project: %+v", app)
+	}
+	if lib.ID != 20 || lib.Path != "lib" || lib.SSHURLToRepo != "git@gitlab.example.com:root/sub/lib.git" || lib.Namespace.FullPath != "root/sub" {
+		t.Errorf("unexpected project: %+v", lib)
+	}
+
+	expected := []string{"rest /api/v4/groups/1", "graphql projects false", "graphql projects true"}
+	if !slices.Equal(*requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, *requests)
+	}
+}
+
+func TestGraphQLGitlab_ListGroupProjects_WithSharedUsesREST(t *testing.T) {
+	client, requests := newGraphQLTestServer(t)
+
+	withShared := true
+	projects, _, err := client.ListGroupProjects(1, &gitlab.ListGroupProjectsOptions{WithShared: &withShared})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(projects) != 1 || projects[0].PathWithNamespace != "other/shared" {
+		t.Errorf("unexpected projects: %v", projects)
+	}
+
+	expected := []string{"rest /api/v4/groups/1/projects"}
+	if !slices.Equal(*requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, *requests)
+	}
+}
+
+func TestGraphQLGitlab_ResponseErrors(t *testing.T) {
+	client, _ := newGraphQLTestServer(t)
+	client.paths[8] = "failing"
+
+	expectedErr := &ErrorGraphQLQuery{[]string{"Timeout on Group.projects"}}
+
+	_, _, err := client.ListGroupProjects(8, &gitlab.ListGroupProjectsOptions{})
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Errorf("expected error %v, got %v", expectedErr, err)
+	}
+
+	_, _, err = client.ListSubGroups("8", &gitlab.ListSubGroupsOptions{})
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Errorf("expected error %v, got %v", expectedErr, err)
+	}
+}
+
+func TestGraphQLGitlab_ListGroupProjects_NullNodes(t *testing.T) {
+	client, _ := newGraphQLTestServer(t)
+	client.paths[9] = "nulls"
+
+	projects, _, err := client.ListGroupProjects(9, &gitlab.ListGroupProjectsOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(projects) != 1 || projects[0].PathWithNamespace != "nulls/app" {
+		t.Errorf("expected only project nulls/app, got %v", projects)
+	}
+}
+
+func TestGraphQLGitlab_GroupNotFound(t *testing.T) {
+	client, _ := newGraphQLTestServer(t)
+	client.paths[7] = "missing"
+
+	_, _, err := client.ListSubGroups("7", &gitlab.ListSubGroupsOptions{})
+
+	expectedErr := &ErrorGraphQLGroupNotFound{"missing"}
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected error %v, got %v", expectedErr, err)
+	}
+
+	var target *ErrorGraphQLGroupNotFound
+	if !errors.As(err, &target) {
+		t.Errorf("expected ErrorGraphQLGroupNotFound, got %T", err)
+	}
+}
+
+func TestGraphQLGitlab_FetchGroupsByIDs(t *testing.T) {
+	client, requests := newGraphQLTestServer(t)
+
+	cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+		config.GroupIDsKey: "root",
+	}))
+
+	groupsChan, errsChan := fetchGroupsByIDs(context.Background(), client, cfg, newSkipGroupsResolver(client, cfg.GetSkipGroupIDs()))
+
+	go func() {
+		for err := range errsChan {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}()
+
+	var groups []string
+	for group := range groupsChan {
+		groups = append(groups, group.fullPath)
+	}
+	slices.Sort(groups)
+
+	expected := []string{"root", "root/skipped", "root/sub", "root/sub/deep"}
+	if !slices.Equal(groups, expected) {
+		t.Errorf("expected groups %v, got %v", expected, groups)
+	}
+
+	graphQLRequests := 0
+	for _, request := range *requests {
+		if strings.HasPrefix(request, "graphql") {
+			graphQLRequests++
+		}
+	}
+	if graphQLRequests != 2 {
+		t.Errorf("expected the tree to be fetched with 2 GraphQL requests, got %v", *requests)
+	}
+}
+
+func TestParseGraphQLID(t *testing.T) {
+	tests := []struct {
+		globalID string
+		expected int
+		err      bool
+	}{
+		{"gid://gitlab/Group/42", 42, false},
+		{"gid://gitlab/Project/7", 7, false},
+		{"gid://gitlab/Group/abc", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.globalID, func(t *testing.T) {
+			id, err := parseGraphQLID(test.globalID)
+			if (err != nil) != test.err || id != test.expected {
+				t.Errorf("expected %d (error %t), got %d, %v", test.expected, test.err, id, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package main
 
-import gitlab "gitlab.com/gitlab-org/api/client-go"
+import (
+	"sync/atomic"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
 
 type GroupsService interface {
 	GetGroup(gid string, opt *gitlab.GetGroupOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Group, *gitlab.Response, error)
@@ -10,10 +14,25 @@ type GroupsService interface {
 
 type ProjectsService interface {
 	ListGroupProjects(gid int, opt *gitlab.ListGroupProjectsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error)
+	ListUserProjects(uid any, opt *gitlab.ListProjectsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error)
+	GetProject(pid any, opt *gitlab.GetProjectOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Project, *gitlab.Response, error)
+	ListProjects(opt *gitlab.ListProjectsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error)
+}
+
+type UsersService interface {
+	CurrentUser(options ...gitlab.RequestOptionFunc) (*gitlab.User, *gitlab.Response, error)
+}
+
+// DiscoveryService is the part of the GitLab API used to discover projects.
+type DiscoveryService interface {
+	GroupsService
+	ProjectsService
+	UsersService
 }
 
 type Gitlab struct {
-	client *gitlab.Client
+	client   *gitlab.Client
+	requests atomic.Int64
 }
 
 func NewGitlab(client *gitlab.Client) *Gitlab {
@@ -23,17 +42,46 @@ func NewGitlab(client *gitlab.Client) *Gitlab {
 }
 
 func (g *Gitlab) GetGroup(gid string, opt *gitlab.GetGroupOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Group, *gitlab.Response, error) {
+	g.requests.Add(1)
 	return g.client.Groups.GetGroup(gid, opt, options...)
 }
 
 func (g *Gitlab) ListGroups(opt *gitlab.ListGroupsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Group, *gitlab.Response, error) {
+	g.requests.Add(1)
 	return g.client.Groups.ListGroups(opt, options...)
 }
 
 func (g *Gitlab) ListSubGroups(gid string, opt *gitlab.ListSubGroupsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Group, *gitlab.Response, error) {
+	g.requests.Add(1)
 	return g.client.Groups.ListSubGroups(gid, opt, options...)
 }
 
 func (g *Gitlab) ListGroupProjects(gid int, opt *gitlab.ListGroupProjectsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error) {
+	g.requests.Add(1)
 	return g.client.Groups.ListGroupProjects(gid, opt, options...)
 }
+
+func (g *Gitlab) ListUserProjects(uid any, opt *gitlab.ListProjectsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error) {
+	g.requests.Add(1)
+	return g.client.Projects.ListUserProjects(uid, opt, options...)
+}
+
+func (g *Gitlab) GetProject(pid any, opt *gitlab.GetProjectOptions, options ...gitlab.RequestOptionFunc) (*gitlab.Project, *gitlab.Response, error) {
+	g.requests.Add(1)
+	return g.client.Projects.GetProject(pid, opt, options...)
+}
+
+func (g *Gitlab) ListProjects(opt *gitlab.ListProjectsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error) {
+	g.requests.Add(1)
+	return g.client.Projects.ListProjects(opt, options...)
+}
+
+func (g *Gitlab) CurrentUser(options ...gitlab.RequestOptionFunc) (*gitlab.User, *gitlab.Response, error) {
+	g.requests.Add(1)
+	return g.client.Users.CurrentUser(options...)
+}
+
+// GetRequests returns the number of API requests made so far, every page of a list is a request.
+func (g *Gitlab) GetRequests() int64 {
+	return g.requests.Load()
+}
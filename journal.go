@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// JournalEntry is a record of a finished project in the run journal.
+// An entry with RunFinished set marks the end of a logical run which wasn't interrupted.
+type JournalEntry struct {
+	RunID       string    `json:"run_id"`
+	ProjectID   int       `json:"project_id,omitempty"`
+	Path        string    `json:"path,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	RunFinished bool      `json:"run_finished,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// Journal is an append-only log of finished projects of a logical run,
+// it allows an interrupted run to be resumed with the projects that were not finished.
+type Journal struct {
+	path     string
+	runID    string
+	file     *os.File
+	finished map[int]*JournalEntry
+	mutex    *sync.Mutex
+}
+
+// OpenJournal opens the journal at the path. When resume is set, the entries of the previous attempts are loaded
+// and new entries are appended to them, otherwise the journal starts a new logical run.
+// A journal whose run is finished starts a new logical run too, so a resume left enabled doesn't skip projects forever.
+func OpenJournal(path string, resume bool) (*Journal, error) {
+	journal := &Journal{
+		path:     path,
+		finished: map[int]*JournalEntry{},
+		mutex:    &sync.Mutex{},
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, &ErrorJournal{path, err}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if resume {
+		finished, err := journal.load()
+		if err != nil {
+			return nil, err
+		}
+		if finished {
+			flags |= os.O_TRUNC
+		}
+	} else {
+		flags |= os.O_TRUNC
+	}
+
+	if journal.runID == "" {
+		journal.runID = time.Now().UTC().Format("20060102T150405.000000000Z")
+	}
+
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, &ErrorJournal{path, err}
+	}
+	journal.file = file
+
+	return journal, nil
+}
+
+// load reads the entries of the previous attempts, a line left incomplete by a crash is ignored.
+// It reports whether the run of the journal is finished, nothing is loaded then.
+func (j *Journal) load() (bool, error) {
+	file, err := os.Open(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, &ErrorJournal{j.path, err}
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	finished := false
+
+	for scanner.Scan() {
+		entry := &JournalEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			continue
+		}
+
+		if entry.RunFinished {
+			finished = true
+			break
+		}
+
+		if j.runID == "" {
+			j.runID = entry.RunID
+		}
+
+		j.finished[entry.ProjectID] = entry
+	}
+
+	if err := scanner.Err(); err != nil {
+		return false, &ErrorJournal{j.path, err}
+	}
+
+	if finished {
+		j.runID = ""
+		clear(j.finished)
+	}
+
+	return finished, nil
+}
+
+func (j *Journal) GetPath() string {
+	return j.path
+}
+
+func (j *Journal) GetRunID() string {
+	return j.runID
+}
+
+// Completed returns the entry of the project if it was successfully finished by a previous attempt of the run.
+func (j *Journal) Completed(projectID int) (*JournalEntry, bool) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	entry, ok := j.finished[projectID]
+	if !ok || parseSyncStatus(entry.Status) == SyncStatusFailed {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// Record appends the result of the project to the journal.
+func (j *Journal) Record(result *Result) error {
+	if result == nil || result.project == nil {
+		return nil
+	}
+
+	entry := &JournalEntry{
+		RunID:     j.runID,
+		ProjectID: result.project.id,
+		Path:      result.project.pathWithNamespace,
+		Status:    result.status.String(),
+		Time:      time.Now().UTC(),
+	}
+	if result.err != nil {
+		entry.Status = SyncStatusFailed.String()
+		entry.Error = result.err.Error()
+	}
+
+	if err := j.write(entry); err != nil {
+		return err
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.finished[entry.ProjectID] = entry
+
+	return nil
+}
+
+// write appends the entry to the journal file.
+func (j *Journal) write(entry *JournalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return &ErrorJournal{j.path, err}
+	}
+	data = append(data, '\n')
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if _, err := j.file.Write(data); err != nil {
+		return &ErrorJournal{j.path, err}
+	}
+
+	if err := j.file.Sync(); err != nil {
+		return &ErrorJournal{j.path, err}
+	}
+
+	return nil
+}
+
+// Finish marks the logical run as finished, the next run starts a new one even with resume.
+func (j *Journal) Finish() error {
+	return j.write(&JournalEntry{RunID: j.runID, RunFinished: true, Time: time.Now().UTC()})
+}
+
+func (j *Journal) Close() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if err := j.file.Close(); err != nil {
+		return &ErrorJournal{j.path, err}
+	}
+
+	return nil
+}
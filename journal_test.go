@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournal_RecordAndResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.journal")
+
+	journal, err := OpenJournal(path, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results := []*Result{
+		{project: &Project{id: 1, pathWithNamespace: "group/cloned"}, status: SyncStatusCloned},
+		{project: &Project{id: 2, pathWithNamespace: "group/failed"}, status: SyncStatusFailed, err: errors.New("fail")},
+		{project: &Project{id: 3, pathWithNamespace: "group/unchanged"}, status: SyncStatusUnchanged},
+		{project: nil, err: errors.New("no project")},
+	}
+	for _, result := range results {
+		if err := journal.Record(result); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	runID := journal.GetRunID()
+	if err := journal.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Simulate a crash in the middle of writing an entry.
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _ = file.WriteString(`{"run_id":"` + runID + `","project_id":4,"sta`)
+	_ = file.Close()
+
+	resumed, err := OpenJournal(path, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = resumed.Close()
+	}()
+
+	if resumed.GetRunID() != runID {
+		t.Errorf("expected run ID %s to be kept, got %s", runID, resumed.GetRunID())
+	}
+
+	if entry, ok := resumed.Completed(1); !ok || entry.Status != SyncStatusCloned.String() {
+		t.Errorf("expected project 1 to be completed, got %v", entry)
+	}
+	if _, ok := resumed.Completed(2); ok {
+		t.Error("expected failed project to be retried")
+	}
+	if _, ok := resumed.Completed(3); !ok {
+		t.Error("expected project 3 to be completed")
+	}
+	if _, ok := resumed.Completed(4); ok {
+		t.Error("expected incomplete entry to be ignored")
+	}
+}
+
+func TestJournal_NewRunTruncates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.journal")
+
+	journal, err := OpenJournal(path, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = journal.Record(&Result{project: &Project{id: 1}, status: SyncStatusCloned})
+	_ = journal.Close()
+
+	journal, err = OpenJournal(path, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = journal.Close()
+	}()
+
+	if _, ok := journal.Completed(1); ok {
+		t.Error("expected a new run to start from scratch")
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil || stat.Size() != 0 {
+		t.Errorf("expected empty journal, got %v, %v", stat, err)
+	}
+}
+
+func TestJournal_ResumeAfterFinishedRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.journal")
+
+	journal, err := OpenJournal(path, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := journal.Record(&Result{project: &Project{id: 1, pathWithNamespace: "group/cloned"}, status: SyncStatusCloned}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := journal.Finish(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	runID := journal.GetRunID()
+	if err := journal.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resumed, err := OpenJournal(path, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = resumed.Close()
+	}()
+
+	if resumed.GetRunID() == runID {
+		t.Errorf("expected a new run ID after the finished run %s", runID)
+	}
+	if entry, ok := resumed.Completed(1); ok {
+		t.Errorf("expected project 1 to be synced again, got %v", entry)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Errorf("expected the finished journal to be truncated, got %v", err)
+	}
+}
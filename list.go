@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+)
+
+// ListedGroup is a group in the project list of a dry run.
+type ListedGroup struct {
+	ID       int    `json:"id"`
+	FullPath string `json:"full_path"`
+}
+
+// ListedProject is a project in the project list of a dry run.
+type ListedProject struct {
+	ID                int      `json:"id"`
+	PathWithNamespace string   `json:"path_with_namespace"`
+	Group             string   `json:"group,omitempty"`
+	Shared            bool     `json:"shared,omitempty"`
+	CloneURL          string   `json:"clone_url"`
+	TargetDir         string   `json:"target_dir"`
+	RepositorySize    *int64   `json:"repository_size,omitempty"`
+	Visibility        string   `json:"visibility,omitempty"`
+	Topics            []string `json:"topics,omitempty"`
+}
+
+// ExcludedListedProject is a project in the project list of a dry run which is not cloned and the rule which excluded it.
+type ExcludedListedProject struct {
+	ID                int    `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	Reason            string `json:"reason"`
+}
+
+// ProjectList is what a run would process: the resolved groups, the groups excluded by RE_SKIP_GROUP_IDS,
+// the projects with their clone URLs and target directories and the projects excluded by filters.
+// Instance is the name of the instance in RE_INSTANCES, empty without RE_INSTANCES.
+type ProjectList struct {
+	Instance         string                  `json:"instance,omitempty"`
+	Groups           []ListedGroup           `json:"groups"`
+	SkippedGroups    []ListedGroup           `json:"skipped_groups"`
+	Projects         []ListedProject         `json:"projects"`
+	ExcludedProjects []ExcludedListedProject `json:"excluded_projects"`
+	Errors           []string                `json:"errors"`
+}
+
+// collectProjectList runs the discovery only and collects its result, nothing is cloned or written to disk.
+func collectProjectList(ctx context.Context, cfg *config.Config, client DiscoveryService) (*ProjectList, error) {
+	list := &ProjectList{
+		Groups:           []ListedGroup{},
+		SkippedGroups:    []ListedGroup{},
+		Projects:         []ListedProject{},
+		ExcludedProjects: []ExcludedListedProject{},
+		Errors:           []string{},
+	}
+
+	skipped := newSkipGroupsResolver(client, cfg.GetSkipGroupIDs())
+
+	groupsChan, discoveredChan, errsChan := discoverProjects(ctx, cfg, client, skipped)
+	projectsChan, excludedChan := selectProjects(ctx, cfg, client, discoveredChan)
+
+	groupsDone := make(chan struct{})
+	go func() {
+		defer close(groupsDone)
+
+		for group := range groupsChan {
+			if group != nil {
+				list.Groups = append(list.Groups, ListedGroup{group.id, group.fullPath})
+			}
+		}
+	}()
+
+	for projectsChan != nil || excludedChan != nil || errsChan != nil {
+		select {
+		case project, ok := <-projectsChan:
+			if !ok {
+				projectsChan = nil
+				continue
+			}
+			if project == nil {
+				continue
+			}
+
+			listed := ListedProject{
+				ID:                project.id,
+				PathWithNamespace: project.pathWithNamespace,
+				Shared:            project.shared,
+				CloneURL:          redactURL(getCloneURL(cfg, project)),
+				TargetDir:         getProjectDir(cfg, project),
+				RepositorySize:    project.repositorySize,
+				Visibility:        project.visibility,
+				Topics:            project.topics,
+			}
+			if project.group != nil {
+				listed.Group = project.group.fullPath
+			}
+
+			list.Projects = append(list.Projects, listed)
+		case excluded, ok := <-excludedChan:
+			if !ok {
+				excludedChan = nil
+				continue
+			}
+
+			list.ExcludedProjects = append(list.ExcludedProjects, ExcludedListedProject{
+				ID:                excluded.project.id,
+				PathWithNamespace: excluded.project.pathWithNamespace,
+				Reason:            excluded.reason,
+			})
+		case err, ok := <-errsChan:
+			if !ok {
+				errsChan = nil
+				continue
+			}
+			if err != nil {
+				list.Errors = append(list.Errors, err.Error())
+			}
+		}
+	}
+
+	<-groupsDone
+
+	// The skipped groups are already resolved by the discovery, which reports their errors too.
+	if walksGroups(cfg) || listsAllProjects(cfg) {
+		if skippedGroups, err := skipped.resolve(ctx); err == nil {
+			for _, group := range skippedGroups {
+				list.SkippedGroups = append(list.SkippedGroups, ListedGroup{group.id, group.fullPath})
+			}
+		}
+	}
+
+	compareGroups := func(a, b ListedGroup) int {
+		return strings.Compare(a.FullPath, b.FullPath)
+	}
+	slices.SortFunc(list.Groups, compareGroups)
+	slices.SortFunc(list.SkippedGroups, compareGroups)
+	slices.SortFunc(list.Projects, func(a, b ListedProject) int {
+		return strings.Compare(a.PathWithNamespace, b.PathWithNamespace)
+	})
+	slices.SortFunc(list.ExcludedProjects, func(a, b ExcludedListedProject) int {
+		return strings.Compare(a.PathWithNamespace, b.PathWithNamespace)
+	})
+
+	if ctx.Err() != nil {
+		return list, ErrorInterrupted
+	}
+
+	if len(list.Errors) > 0 {
+		return list, &ErrorDiscoveryFailed{uint32(len(list.Errors))}
+	}
+
+	return list, nil
+}
+
+// writeProjectList prints the project list in the format.
+func writeProjectList(out io.Writer, format config.ListFormat, list *ProjectList) error {
+	switch format {
+	case config.ListFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(list)
+	case config.ListFormatNDJSON:
+		return writeProjectListNDJSON(out, list)
+	default:
+		return writeProjectListTable(out, list)
+	}
+}
+
+// writeProjectLists prints the project lists of all instances, the json format prints an array of them
+// unless there is a single list.
+func writeProjectLists(out io.Writer, format config.ListFormat, lists []*ProjectList) error {
+	if len(lists) == 1 {
+		return writeProjectList(out, format, lists[0])
+	}
+
+	if format == config.ListFormatJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(lists)
+	}
+
+	for index, list := range lists {
+		if index > 0 && format == config.ListFormatTable {
+			_, _ = fmt.Fprintln(out)
+		}
+
+		if err := writeProjectList(out, format, list); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeProjectListNDJSON prints every group, skipped group, project, excluded project and error as a JSON object with a type field.
+func writeProjectListNDJSON(out io.Writer, list *ProjectList) error {
+	type groupRecord struct {
+		Type     string `json:"type"`
+		Instance string `json:"instance,omitempty"`
+		ListedGroup
+	}
+
+	type projectRecord struct {
+		Type     string `json:"type"`
+		Instance string `json:"instance,omitempty"`
+		ListedProject
+	}
+
+	type excludedProjectRecord struct {
+		Type     string `json:"type"`
+		Instance string `json:"instance,omitempty"`
+		ExcludedListedProject
+	}
+
+	type errorRecord struct {
+		Type     string `json:"type"`
+		Instance string `json:"instance,omitempty"`
+		Error    string `json:"error"`
+	}
+
+	var records []any
+	for _, group := range list.Groups {
+		records = append(records, groupRecord{"group", list.Instance, group})
+	}
+	for _, group := range list.SkippedGroups {
+		records = append(records, groupRecord{"skipped_group", list.Instance, group})
+	}
+	for _, project := range list.Projects {
+		records = append(records, projectRecord{"project", list.Instance, project})
+	}
+	for _, project := range list.ExcludedProjects {
+		records = append(records, excludedProjectRecord{"excluded_project", list.Instance, project})
+	}
+	for _, err := range list.Errors {
+		records = append(records, errorRecord{"error", list.Instance, err})
+	}
+
+	encoder := json.NewEncoder(out)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeProjectListTable prints the group tree, the skipped groups, a table of projects and the excluded projects.
+func writeProjectListTable(out io.Writer, list *ProjectList) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	paths := map[string]struct{}{}
+	for _, group := range list.Groups {
+		paths[group.FullPath] = struct{}{}
+	}
+
+	if list.Instance != "" {
+		_, _ = fmt.Fprintf(writer, "Instance: %s\n\n", list.Instance)
+	}
+
+	_, _ = fmt.Fprintf(writer, "Groups (%d):\n", len(list.Groups))
+	for _, group := range list.Groups {
+		depth := 0
+		for index := range len(group.FullPath) {
+			if group.FullPath[index] != '/' {
+				continue
+			}
+			if _, ok := paths[group.FullPath[:index]]; ok {
+				depth++
+			}
+		}
+		_, _ = fmt.Fprintf(writer, "%s%s\n", strings.Repeat("  ", depth+1), group.FullPath)
+	}
+
+	_, _ = fmt.Fprintf(writer, "\nSkipped groups (%d):\n", len(list.SkippedGroups))
+	for _, group := range list.SkippedGroups {
+		_, _ = fmt.Fprintf(writer, "  %s\n", group.FullPath)
+	}
+
+	_, _ = fmt.Fprintf(writer, "\nProjects (%d):\n", len(list.Projects))
+	_, _ = fmt.Fprintln(writer, "ID\tPATH\tCLONE URL\tTARGET DIR\tSIZE\tVISIBILITY\tTOPICS")
+	for _, project := range list.Projects {
+		size := ""
+		if project.RepositorySize != nil {
+			size = formatSize(*project.RepositorySize)
+		}
+		_, _ = fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", project.ID, project.PathWithNamespace, project.CloneURL,
+			project.TargetDir, size, project.Visibility, strings.Join(project.Topics, ", "))
+	}
+
+	if len(list.ExcludedProjects) > 0 {
+		_, _ = fmt.Fprintf(writer, "\nExcluded projects (%d):\n", len(list.ExcludedProjects))
+		_, _ = fmt.Fprintln(writer, "ID\tPATH\tREASON")
+		for _, project := range list.ExcludedProjects {
+			_, _ = fmt.Fprintf(writer, "%d\t%s\t%s\n", project.ID, project.PathWithNamespace, project.Reason)
+		}
+	}
+
+	if len(list.Errors) > 0 {
+		_, _ = fmt.Fprintf(writer, "\nErrors (%d):\n", len(list.Errors))
+		for _, err := range list.Errors {
+			_, _ = fmt.Fprintf(writer, "  %s\n", err)
+		}
+	}
+
+	return writer.Flush()
+}
+
+// redactURL hides the password or token in the URL, URLs without credentials are returned as is.
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.User == nil {
+		return rawURL
+	}
+
+	return parsed.Redacted()
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"log"
 	"os"
 
@@ -10,21 +11,32 @@ import (
 func main() {
 	cfg := config.GetConfig()
 
-	if cfg == nil || cfg.GetAccessToken() == "" {
+	if err := cfg.Validate(); err != nil {
 		log.SetOutput(os.Stderr)
-		log.Println("Error: GITLAB_TOKEN environment variable is required")
-		log.Println("Please set your GitLab access token:")
-		log.Println()
-		log.Println("\texport RE_GITLAB_TOKEN=your_token_here")
-		log.Println()
-		log.Println("or in .env.local file:")
-		log.Println()
-		log.Println("\tRE_GITLAB_TOKEN=your_token_here")
-		log.Fatalln()
+		log.Println("Error: invalid configuration")
+		log.Println(err)
+
+		if errors.Is(err, config.ErrorMissingAccessToken) {
+			log.Println()
+			log.Println("Please set your GitLab access token:")
+			log.Println()
+			log.Println("\texport RE_GITLAB_TOKEN=your_token_here")
+			log.Println()
+			log.Println("or in .env.local file:")
+			log.Println()
+			log.Println("\tRE_GITLAB_TOKEN=your_token_here")
+		}
+
+		os.Exit(ExitCodeConfigError)
+	}
+
+	// The project list of a dry run is printed to stdout, so the log stays on stderr.
+	if !cfg.GetDryRun() {
+		log.SetOutput(os.Stdout)
 	}
 
-	log.SetOutput(os.Stdout)
 	if err := run(); err != nil {
-		log.Fatalln(err)
+		log.Println(err)
+		os.Exit(exitCodeOf(err))
 	}
 }
@@ -2,14 +2,23 @@ package main
 
 import (
 	"context"
+	"io"
+	"io/fs"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"time"
 )
 
+// commandWaitDelay is how long a canceled command is given to exit after the interrupt before it is killed.
+const commandWaitDelay = 10 * time.Second
+
 type OSWrapper interface {
 	MakeDirAll(path string) error
 	IsDirExists(path string) (bool, error)
 	RemoveAll(path string) error
+	Rename(oldPath, newPath string) error
+	CopyDir(src, dst string) error
 	ExecuteCommand(ctx context.Context, cmd string, args ...string) ([]byte, error)
 }
 
@@ -40,8 +49,74 @@ func (w *DefaultOSWrapper) RemoveAll(path string) error {
 	return os.RemoveAll(path)
 }
 
+func (w *DefaultOSWrapper) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
+// CopyDir copies the directory tree with its files, symbolic links and permissions to a new directory.
+func (w *DefaultOSWrapper) CopyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, relPath)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case entry.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case entry.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
 func (w *DefaultOSWrapper) ExecuteCommand(ctx context.Context, cmd string, args ...string) ([]byte, error) {
 	command := exec.CommandContext(ctx, cmd, args...)
+	// Interrupt the command on cancellation, so git can clean up after itself.
+	command.Cancel = func() error {
+		return command.Process.Signal(os.Interrupt)
+	}
+	command.WaitDelay = commandWaitDelay
 	return command.CombinedOutput()
 }
 
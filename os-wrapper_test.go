@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 )
 
 var dirName = path.Join(os.TempDir(), "test_dir_name")
@@ -108,6 +109,25 @@ func TestDefaultOSWrapper_RemoveAll(t *testing.T) {
 	}
 }
 
+func TestDefaultOSWrapper_Rename(t *testing.T) {
+	w := GetDefaultOSWrapper()
+
+	oldDir := path.Join(dirName, "test_rename_old")
+	newDir := path.Join(dirName, "test_rename_new")
+	_ = os.MkdirAll(oldDir, 0o755)
+
+	err := w.Rename(oldDir, newDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = os.Stat(oldDir); !os.IsNotExist(err) {
+		t.Error("expected old directory to be moved")
+	}
+	if _, err = os.Stat(newDir); err != nil {
+		t.Errorf("expected new directory to exist: %v", err)
+	}
+}
+
 func TestDefaultOSWrapper_ExecuteCommand(t *testing.T) {
 	w := GetDefaultOSWrapper()
 
@@ -129,3 +149,23 @@ func TestDefaultOSWrapper_ExecuteCommand(t *testing.T) {
 		t.Errorf("expected output, got empty string")
 	}
 }
+
+func TestDefaultOSWrapper_ExecuteCommand_Cancel(t *testing.T) {
+	if os.PathSeparator == '\\' {
+		t.Skip("sleep command is not available")
+	}
+
+	w := GetDefaultOSWrapper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err := w.ExecuteCommand(ctx, "sleep", "5")
+	if err == nil {
+		t.Fatal("expected error for canceled command")
+	}
+	if time.Since(started) > 2*time.Second {
+		t.Errorf("expected command to be interrupted, took %s", time.Since(started))
+	}
+}
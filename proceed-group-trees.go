@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"log"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// proceedGroupTrees lists the projects of every group tree with a single paginated request to its root group
+// instead of a request per group. It waits for all groups to be discovered to find the roots, a root is a group
+// none of whose ancestors was discovered. Projects of groups which were not discovered, e.g. skipped ones,
+// are dropped and shared projects are assigned to the group they are shared with, so the result is the same
+// as of proceedGroups.
+// When the request to a root fails, the groups of its tree are walked one by one with proceedGroups.
+func proceedGroupTrees(ctx context.Context, client ProjectsService, cfg *config.Config, groupsChan <-chan *Group) (<-chan *Project, <-chan error) {
+	dataChan := make(chan *Project)
+	fallbackChan := make(chan *Group)
+
+	walkedChan, errsChan := proceedGroups(ctx, client, cfg, fallbackChan)
+
+	go func() {
+		defer func() {
+			close(dataChan)
+			close(fallbackChan)
+		}()
+
+		groups := map[string]*Group{}
+
+		for groupsChan != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case group, ok := <-groupsChan:
+				if !ok {
+					groupsChan = nil
+					continue
+				}
+				if group != nil {
+					groups[group.fullPath] = group
+				}
+			}
+		}
+
+		rootsChan := make(chan *Group)
+		go func() {
+			defer close(rootsChan)
+
+			for _, root := range findRootGroups(groups) {
+				select {
+				case <-ctx.Done():
+					return
+				case rootsChan <- root:
+				}
+			}
+		}()
+
+		maxWorkers := cfg.GetMaxWorkers()
+		query := newProjectsQuery(cfg)
+
+		wg := &sync.WaitGroup{}
+		wg.Add(maxWorkers)
+
+		for range maxWorkers {
+			go func() {
+				defer wg.Done()
+
+				for root := range rootsChan {
+					err := listGroupTreeProjects(ctx, client, root, groups, cfg.GetWithShared(), query, dataChan)
+					if err == nil || ctx.Err() != nil {
+						continue
+					}
+
+					log.Printf("Failed to list projects of group %s with subgroups, walking its groups: %v\n", root.fullPath, err)
+
+					for _, group := range groups {
+						if !isInGroupTree(group.fullPath, root.fullPath) {
+							continue
+						}
+
+						select {
+						case <-ctx.Done():
+							return
+						case fallbackChan <- group:
+						}
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+	}()
+
+	return mergeChans(ctx, dataChan, walkedChan), errsChan
+}
+
+// findRootGroups returns the groups none of whose ancestors are in the groups, sorted by path.
+func findRootGroups(groups map[string]*Group) []*Group {
+	var roots []*Group
+
+	for fullPath, group := range groups {
+		isRoot := true
+		for parent := path.Dir(fullPath); parent != "." && parent != "/"; parent = path.Dir(parent) {
+			if _, ok := groups[parent]; ok {
+				isRoot = false
+				break
+			}
+		}
+
+		if isRoot {
+			roots = append(roots, group)
+		}
+	}
+
+	slices.SortFunc(roots, func(a, b *Group) int {
+		return strings.Compare(a.fullPath, b.fullPath)
+	})
+
+	return roots
+}
+
+// isInGroupTree reports whether the path is the root group or one of its descendants.
+func isInGroupTree(fullPath, rootPath string) bool {
+	return fullPath == rootPath || strings.HasPrefix(fullPath, rootPath+"/")
+}
+
+// listGroupTreeProjects sends the projects of the root group and all its subgroups to the channel.
+// Every project is assigned to the discovered group of its namespace, projects of other namespaces are shared
+// with the tree and assigned to the discovered group they are shared with.
+func listGroupTreeProjects(
+	ctx context.Context,
+	client ProjectsService,
+	root *Group,
+	groups map[string]*Group,
+	withShared bool,
+	query *projectsQuery,
+	dataChan chan<- *Project,
+) error {
+	subGroups := true
+	opt := &gitlab.ListGroupProjectsOptions{
+		IncludeSubGroups: &subGroups,
+		WithShared:       &withShared,
+	}
+	query.applyToGroupProjects(opt)
+	opt.PerPage = 100
+
+	for {
+		projects, resp, err := client.ListGroupProjects(root.id, opt, gitlab.WithContext(ctx))
+		if err != nil {
+			return &ErrorProjectsFetching{root.id, err}
+		}
+
+		for _, project := range projects {
+			if project == nil {
+				continue
+			}
+
+			namespace := path.Dir(project.PathWithNamespace)
+			if project.Namespace != nil {
+				namespace = project.Namespace.FullPath
+			}
+
+			prepared := newProject(project, root)
+
+			if group, ok := groups[namespace]; ok {
+				prepared.group = group
+			} else if isInGroupTree(namespace, root.fullPath) {
+				// A subgroup which was skipped.
+				continue
+			} else if group := findSharedGroup(project, root, groups); group != nil {
+				prepared.group = group
+				prepared.shared = true
+			} else {
+				// Shared with skipped subgroups only.
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case dataChan <- prepared:
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+
+	return nil
+}
+
+// findSharedGroup returns the first discovered group of the tree the project is shared with, or nil when it is
+// shared only with groups of the tree which were not discovered. The root group is returned when GitLab
+// doesn't report any group of the tree the project is shared with.
+func findSharedGroup(project *gitlab.Project, root *Group, groups map[string]*Group) *Group {
+	inTree := false
+
+	for _, shared := range project.SharedWithGroups {
+		if !isInGroupTree(shared.GroupFullPath, root.fullPath) {
+			continue
+		}
+
+		if group, ok := groups[shared.GroupFullPath]; ok {
+			return group
+		}
+		inTree = true
+	}
+
+	if inTree {
+		return nil
+	}
+
+	return root
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+type FakeGitlabGroupTree struct {
+	*FakeGitlabProjects
+	trees   map[int][]*gitlab.Project
+	treeErr error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (f *FakeGitlabGroupTree) ListGroupProjects(gid int, opt *gitlab.ListGroupProjectsOptions, options ...gitlab.RequestOptionFunc) ([]*gitlab.Project, *gitlab.Response, error) {
+	withSubGroups := opt.IncludeSubGroups != nil && *opt.IncludeSubGroups
+
+	f.mu.Lock()
+	f.calls = append(f.calls, fmt.Sprintf("%d:%t", gid, withSubGroups))
+	f.mu.Unlock()
+
+	if !withSubGroups {
+		return f.FakeGitlabProjects.ListGroupProjects(gid, opt, options...)
+	}
+
+	if f.treeErr != nil {
+		return nil, nil, f.treeErr
+	}
+
+	return f.trees[gid], &gitlab.Response{}, nil
+}
+
+func getGroupTreeFixture() (map[string]*Group, *FakeGitlabGroupTree) {
+	groups := map[string]*Group{
+		"root":     {id: 1, fullPath: "root"},
+		"root/sub": {id: 2, fullPath: "root/sub"},
+		"other":    {id: 5, fullPath: "other"},
+	}
+
+	namespace := func(fullPath string) *gitlab.ProjectNamespace {
+		return &gitlab.ProjectNamespace{FullPath: fullPath}
+	}
+
+	client := &FakeGitlabGroupTree{
+		FakeGitlabProjects: &FakeGitlabProjects{
+			projects: map[int]map[int]*gitlab.Project{
+				1: {10: {ID: 10, PathWithNamespace: "root/app", Namespace: namespace("root")}},
+				2: {20: {ID: 20, PathWithNamespace: "root/sub/lib", Namespace: namespace("root/sub")}},
+				5: {50: {ID: 50, PathWithNamespace: "other/tool", Namespace: namespace("other")}},
+			},
+		},
+		trees: map[int][]*gitlab.Project{
+			1: {
+				{ID: 10, PathWithNamespace: "root/app", Namespace: namespace("root")},
+				{ID: 20, PathWithNamespace: "root/sub/lib", Namespace: namespace("root/sub")},
+				{ID: 30, PathWithNamespace: "root/skipped/secret", Namespace: namespace("root/skipped")},
+			},
+			5: {{ID: 50, PathWithNamespace: "other/tool", Namespace: namespace("other")}},
+		},
+	}
+
+	return groups, client
+}
+
+func TestFindRootGroups(t *testing.T) {
+	groups := map[string]*Group{
+		"root":              {id: 1, fullPath: "root"},
+		"root/sub":          {id: 2, fullPath: "root/sub"},
+		"root/sub/deep":     {id: 3, fullPath: "root/sub/deep"},
+		"hidden/visible":    {id: 4, fullPath: "hidden/visible"},
+		"hidden/visible/in": {id: 5, fullPath: "hidden/visible/in"},
+		"rooted":            {id: 6, fullPath: "rooted"},
+	}
+
+	var roots []string
+	for _, root := range findRootGroups(groups) {
+		roots = append(roots, root.fullPath)
+	}
+
+	expected := []string{"hidden/visible", "root", "rooted"}
+	if !slices.Equal(roots, expected) {
+		t.Errorf("expected roots %v, got %v", expected, roots)
+	}
+}
+
+func TestProceedGroupTrees(t *testing.T) {
+	tests := []struct {
+		name          string
+		treeErr       error
+		expectedCalls []string
+	}{
+		{
+			name:          "lists every tree with one request",
+			expectedCalls: []string{"1:true", "5:true"},
+		},
+		{
+			name:          "walks the groups when listing a tree fails",
+			treeErr:       errors.New("500 Internal Server Error"),
+			expectedCalls: []string{"1:false", "1:true", "2:false", "5:false", "5:true"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			groups, client := getGroupTreeFixture()
+			client.treeErr = test.treeErr
+
+			cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+				config.MaxWorkersKey: "2",
+			}))
+
+			groupsChan := make(chan *Group)
+			go func() {
+				defer close(groupsChan)
+
+				for _, group := range groups {
+					groupsChan <- group
+				}
+			}()
+
+			projectsChan, errsChan := proceedGroupTrees(context.Background(), client, cfg, groupsChan)
+
+			received := map[string]string{}
+			for projectsChan != nil || errsChan != nil {
+				select {
+				case project, ok := <-projectsChan:
+					if !ok {
+						projectsChan = nil
+						continue
+					}
+					received[project.pathWithNamespace] = project.group.fullPath
+				case err, ok := <-errsChan:
+					if !ok {
+						errsChan = nil
+						continue
+					}
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			expected := map[string]string{
+				"root/app":     "root",
+				"root/sub/lib": "root/sub",
+				"other/tool":   "other",
+			}
+			if len(received) != len(expected) {
+				t.Errorf("expected projects %v, got %v", expected, received)
+			}
+			for project, group := range expected {
+				if received[project] != group {
+					t.Errorf("expected project %s in group %s, got %q", project, group, received[project])
+				}
+			}
+
+			slices.Sort(client.calls)
+			if !slices.Equal(client.calls, test.expectedCalls) {
+				t.Errorf("expected requests %v, got %v", test.expectedCalls, client.calls)
+			}
+		})
+	}
+}
+
+func TestListGroupTreeProjects_Shared(t *testing.T) {
+	groups, client := getGroupTreeFixture()
+	client.trees[1] = append(client.trees[1],
+		&gitlab.Project{
+			ID:                40,
+			PathWithNamespace: "elsewhere/shared",
+			Namespace:         &gitlab.ProjectNamespace{FullPath: "elsewhere"},
+		},
+		sharedWith(t, &gitlab.Project{
+			ID:                41,
+			PathWithNamespace: "elsewhere/lib",
+			Namespace:         &gitlab.ProjectNamespace{FullPath: "elsewhere"},
+		}, "other", "root/sub"),
+		sharedWith(t, &gitlab.Project{
+			ID:                42,
+			PathWithNamespace: "elsewhere/secret",
+			Namespace:         &gitlab.ProjectNamespace{FullPath: "elsewhere"},
+		}, "root/skipped"),
+	)
+
+	dataChan := make(chan *Project, 10)
+	if err := listGroupTreeProjects(context.Background(), client, groups["root"], groups, true, &projectsQuery{}, dataChan); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	close(dataChan)
+
+	var shared []string
+	for project := range dataChan {
+		if project.shared {
+			shared = append(shared, project.pathWithNamespace+"@"+project.group.fullPath)
+		}
+	}
+
+	expected := []string{"elsewhere/shared@root", "elsewhere/lib@root/sub"}
+	if !slices.Equal(shared, expected) {
+		t.Errorf("expected shared projects %v, got %v", expected, shared)
+	}
+}
+
+// sharedWith sets the groups the project is shared with, their type is an anonymous struct of the client.
+func sharedWith(t *testing.T, project *gitlab.Project, groupPaths ...string) *gitlab.Project {
+	t.Helper()
+
+	groups := make([]map[string]string, 0, len(groupPaths))
+	for _, groupPath := range groupPaths {
+		groups = append(groups, map[string]string{"group_full_path": groupPath})
+	}
+
+	data, err := json.Marshal(groups)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal(data, &project.SharedWithGroups); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return project
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/artzub/gitlab-repo-extractor/config"
 )
 
-func proceedGroups(ctx context.Context, client ProjectsService, groupsChan <-chan *Group) (<-chan *Project, <-chan error) {
+func proceedGroups(ctx context.Context, client ProjectsService, cfg *config.Config, groupsChan <-chan *Group) (<-chan *Project, <-chan error) {
 	dataChan := make(chan *Project)
 	errsChan := make(chan error)
 
@@ -17,8 +17,8 @@ func proceedGroups(ctx context.Context, client ProjectsService, groupsChan <-cha
 			close(errsChan)
 		}()
 
-		cfg := config.GetConfig()
 		maxWorkers := cfg.GetMaxWorkers()
+		query := newProjectsQuery(cfg)
 
 		wg := &sync.WaitGroup{}
 		wg.Add(maxWorkers)
@@ -40,7 +40,7 @@ func proceedGroups(ctx context.Context, client ProjectsService, groupsChan <-cha
 							continue
 						}
 
-						projectsChan, projErrsChan := fetchProjectByGroup(ctx, client, group)
+						projectsChan, projErrsChan := fetchProjectByGroup(ctx, client, group, cfg.GetWithShared(), query)
 
 						for projectsChan != nil || projErrsChan != nil {
 							select {
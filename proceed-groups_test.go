@@ -18,7 +18,7 @@ func TestProceedGroups(t *testing.T) {
 		{
 			name: "Ignore nil group",
 			fn: func(t *testing.T) (<-chan *Project, <-chan error) {
-				config.GetConfig(config.NewMemoryEnvLoader(map[string]string{}))
+				cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
 
 				groupsChan := make(chan *Group)
 				go func() {
@@ -27,7 +27,7 @@ func TestProceedGroups(t *testing.T) {
 					groupsChan <- nil
 				}()
 
-				dataChan, errsChan := proceedGroups(context.Background(), &FakeGitlabProjects{}, groupsChan)
+				dataChan, errsChan := proceedGroups(context.Background(), &FakeGitlabProjects{}, cfg, groupsChan)
 
 				select {
 				case project, ok := <-dataChan:
@@ -46,7 +46,7 @@ func TestProceedGroups(t *testing.T) {
 		{
 			name: "Correct proxying errors",
 			fn: func(t *testing.T) (<-chan *Project, <-chan error) {
-				config.GetConfig(config.NewMemoryEnvLoader(map[string]string{}))
+				cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
 
 				groupsChan := make(chan *Group)
 				go func() {
@@ -61,7 +61,7 @@ func TestProceedGroups(t *testing.T) {
 					fetchErr: fetchErr,
 				}
 
-				dataChan, errsChan := proceedGroups(context.Background(), fakeProjects, groupsChan)
+				dataChan, errsChan := proceedGroups(context.Background(), fakeProjects, cfg, groupsChan)
 
 				select {
 				case project, ok := <-dataChan:
@@ -83,6 +83,7 @@ func TestProceedGroups(t *testing.T) {
 				ctx, cancel := context.WithCancel(context.Background())
 				cancel()
 
+				cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
 				projects := getFakeProjects()
 
 				var dataChan <-chan *Project
@@ -103,7 +104,7 @@ func TestProceedGroups(t *testing.T) {
 
 					dataChan, errsChan = proceedGroups(ctx, &FakeGitlabProjects{
 						projects: projects,
-					}, groupsChan)
+					}, cfg, groupsChan)
 				}()
 				wg.Wait()
 
@@ -128,6 +129,7 @@ func TestProceedGroups(t *testing.T) {
 		{
 			name: "Fetch projects successfully",
 			fn: func(t *testing.T) (<-chan *Project, <-chan error) {
+				cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
 				projects := getFakeProjects()
 				groupsChan := make(chan *Group)
 				go func() {
@@ -138,7 +140,7 @@ func TestProceedGroups(t *testing.T) {
 
 				dataChan, errsChan := proceedGroups(context.Background(), &FakeGitlabProjects{
 					projects: projects,
-				}, groupsChan)
+				}, cfg, groupsChan)
 
 				received := map[int]struct{}{}
 
@@ -2,24 +2,36 @@ package main
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
+	"syscall"
 
 	"github.com/artzub/gitlab-repo-extractor/config"
 )
 
 type Result struct {
-	project *Project
-	err     error
+	project   *Project
+	status    SyncStatus
+	err       error
+	movedFrom string
+	resumed   bool
 }
 
-func proceedProjects(ctx context.Context, cloner Cloner, projectsChan <-chan *Project) <-chan *Result {
+func proceedProjects(
+	ctx context.Context,
+	cfg *config.Config,
+	cloner Cloner,
+	projectsChan <-chan *Project,
+	store *StateStore,
+	journal *Journal,
+) <-chan *Result {
 	resultsChan := make(chan *Result)
 
 	go func() {
 		defer close(resultsChan)
 
-		cfg := config.GetConfig()
 		maxWorkers := cfg.GetMaxWorkers()
 		outputDir := cfg.GetOutputDir()
 
@@ -54,11 +66,16 @@ func proceedProjects(ctx context.Context, cloner Cloner, projectsChan <-chan *Pr
 							outputDirExists.Store(mkErr == nil)
 						})
 
+						if !waitForUpstream(ctx, project) {
+							return
+						}
+
 						if !outputDirExists.Load() {
+							markSynced(project)
 							outputDirNotifyOnce.Do(func() {
 								select {
 								case <-ctx.Done():
-								case resultsChan <- &Result{nil, &ErrorOutputDirNotCreated{
+								case resultsChan <- &Result{project: nil, status: SyncStatusFailed, err: &ErrorOutputDirNotCreated{
 									outputDir,
 									outputDirErr,
 								}}:
@@ -67,11 +84,23 @@ func proceedProjects(ctx context.Context, cloner Cloner, projectsChan <-chan *Pr
 							return
 						}
 
-						err := cloner.CloneProjectWithRetry(ctx, cfg, project)
+						var result *Result
+						if entry, ok := journalCompleted(journal, project); ok {
+							result = &Result{project: project, status: parseSyncStatus(entry.Status), resumed: true}
+						} else {
+							result = syncProject(ctx, cfg, cloner, store, project)
+							if journal != nil && ctx.Err() == nil {
+								if err := journal.Record(result); err != nil && result.err == nil {
+									result.err = err
+								}
+							}
+						}
+						markSynced(project)
+
 						select {
 						case <-ctx.Done():
 							return
-						case resultsChan <- &Result{project, err}:
+						case resultsChan <- result:
 						}
 					}
 				}
@@ -83,3 +112,146 @@ func proceedProjects(ctx context.Context, cloner Cloner, projectsChan <-chan *Pr
 
 	return resultsChan
 }
+
+func journalCompleted(journal *Journal, project *Project) (*JournalEntry, bool) {
+	if journal == nil {
+		return nil, false
+	}
+
+	return journal.Completed(project.id)
+}
+
+// syncProject clones or updates the project, projects known to the state store as unchanged are skipped,
+// and clones of renamed or transferred projects are moved to the new location and updated.
+func syncProject(ctx context.Context, cfg *config.Config, cloner Cloner, store *StateStore, project *Project) *Result {
+	if store == nil {
+		status, err := cloner.CloneProjectWithRetry(ctx, cfg, project)
+		return &Result{project: project, status: status, err: err}
+	}
+
+	projectDir := getProjectDir(cfg, project)
+
+	if store.IsUnchanged(project, projectDir) {
+		if exists, _ := cloner.GetOSWrapper().IsDirExists(projectDir); exists && isRemoteUnchanged(ctx, cfg, cloner, store, project) {
+			store.Record(project, projectDir, SyncStatusUnchanged, nil, nil)
+			return &Result{project: project, status: SyncStatusUnchanged}
+		}
+	}
+
+	result := &Result{project: project}
+
+	movedFrom, err := relocateProject(ctx, cfg, cloner.GetOSWrapper(), store, project, projectDir)
+	switch {
+	case err != nil:
+		result.status, result.err = SyncStatusFailed, err
+	case movedFrom != "":
+		result.movedFrom = movedFrom
+		result.status, result.err = cloner.UpdateProjectWithRetry(ctx, cfg, project)
+	default:
+		result.status, result.err = cloner.CloneProjectWithRetry(ctx, cfg, project)
+	}
+
+	var refs map[string]string
+	if result.err == nil {
+		refs, _ = cloner.GetRefs(ctx, cfg, project)
+	}
+
+	store.Record(project, projectDir, result.status, refs, result.err)
+
+	return result
+}
+
+// isRemoteUnchanged confirms that a project without new activity has no new commits either: the refs of the remote
+// must match the refs recorded at the last sync. A project whose remote can't be listed is synced.
+func isRemoteUnchanged(ctx context.Context, cfg *config.Config, cloner Cloner, store *StateStore, project *Project) bool {
+	state, ok := store.Get(project.id)
+	if !ok || len(state.Refs) == 0 {
+		return false
+	}
+
+	remote, err := cloner.GetRemoteRefs(ctx, cfg, project)
+	if err != nil {
+		return false
+	}
+
+	return matchesRemoteRefs(cfg.GetCloneMode(), state.Refs, remote)
+}
+
+// relocateProject moves the clone of the project from the local path known to the state store
+// to the new project directory when the project was renamed or transferred to another namespace.
+// It returns the previous location when the clone was moved.
+func relocateProject(
+	ctx context.Context,
+	cfg *config.Config,
+	osWrapper OSWrapper,
+	store *StateStore,
+	project *Project,
+	projectDir string,
+) (string, error) {
+	state, ok := store.Get(project.id)
+	if !ok || state.LocalPath == "" || state.LocalPath == projectDir {
+		return "", nil
+	}
+
+	oldExists, err := osWrapper.IsDirExists(state.LocalPath)
+	if err != nil || !oldExists {
+		return "", nil
+	}
+
+	newExists, err := osWrapper.IsDirExists(projectDir)
+	if err != nil {
+		return "", &ErrorDirExistsCheck{projectDir, err}
+	}
+	if newExists {
+		return "", nil
+	}
+
+	if err := osWrapper.MakeDirAll(filepath.Dir(projectDir)); err != nil {
+		return "", &ErrorProjectRelocation{state.LocalPath, projectDir, err}
+	}
+
+	// Forks borrowing objects of the old clone would break.
+	references, err := findForkReferences(cfg)
+	if err == nil {
+		err = dissociateForks(ctx, osWrapper, references, state.LocalPath)
+	}
+	if err != nil {
+		return "", &ErrorProjectRelocation{state.LocalPath, projectDir, err}
+	}
+
+	if err := moveDir(osWrapper, state.LocalPath, projectDir, getStagingDir(cfg, project)); err != nil {
+		return "", &ErrorProjectRelocation{state.LocalPath, projectDir, err}
+	}
+
+	return state.LocalPath, nil
+}
+
+// moveDir moves the directory by renaming it. A directory which can't be renamed to another filesystem,
+// e.g. of a project moved into or out of the archived output directory, is copied into the staging directory
+// next to the target and renamed from there, so the target never holds a partial copy.
+func moveDir(osWrapper OSWrapper, from, to, stagingDir string) error {
+	err := osWrapper.Rename(from, to)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	if err := osWrapper.RemoveAll(stagingDir); err != nil {
+		return err
+	}
+
+	if err := osWrapper.MakeDirAll(filepath.Dir(stagingDir)); err != nil {
+		return err
+	}
+
+	if err := osWrapper.CopyDir(from, stagingDir); err != nil {
+		_ = osWrapper.RemoveAll(stagingDir)
+		return err
+	}
+
+	if err := osWrapper.Rename(stagingDir, to); err != nil {
+		_ = osWrapper.RemoveAll(stagingDir)
+		return err
+	}
+
+	return osWrapper.RemoveAll(from)
+}
@@ -3,6 +3,11 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -12,17 +17,46 @@ import (
 type mockCloner struct {
 	osWrapper       OSWrapper
 	projectCloneErr error
+	refs            map[string]string
+	remoteRefs      map[string]string
+	cloned          []*Project
+	updated         []*Project
+	mutex           sync.Mutex
 }
 
 func (m *mockCloner) GetOSWrapper() OSWrapper {
 	return m.osWrapper
 }
 
-func (m *mockCloner) cloneProject(_ context.Context, _ *config.Config, _ *Project) error {
-	return m.projectCloneErr
+func (m *mockCloner) cloneProject(_ context.Context, _ *config.Config, project *Project) (SyncStatus, error) {
+	m.mutex.Lock()
+	m.cloned = append(m.cloned, project)
+	m.mutex.Unlock()
+
+	if m.projectCloneErr != nil {
+		return SyncStatusFailed, m.projectCloneErr
+	}
+
+	return SyncStatusCloned, nil
+}
+
+func (m *mockCloner) UpdateProjectWithRetry(_ context.Context, _ *config.Config, project *Project) (SyncStatus, error) {
+	m.mutex.Lock()
+	m.updated = append(m.updated, project)
+	m.mutex.Unlock()
+
+	return SyncStatusUpdated, nil
 }
 
-func (m *mockCloner) CloneProjectWithRetry(ctx context.Context, cfg *config.Config, project *Project) error {
+func (m *mockCloner) GetRefs(_ context.Context, _ *config.Config, _ *Project) (map[string]string, error) {
+	return m.refs, nil
+}
+
+func (m *mockCloner) GetRemoteRefs(_ context.Context, _ *config.Config, _ *Project) (map[string]string, error) {
+	return m.remoteRefs, nil
+}
+
+func (m *mockCloner) CloneProjectWithRetry(ctx context.Context, cfg *config.Config, project *Project) (SyncStatus, error) {
 	return m.cloneProject(ctx, cfg, project)
 }
 
@@ -35,7 +69,7 @@ func TestProceedProjects(t *testing.T) {
 			name: "error result if output directory is not created",
 			fn: func(t *testing.T) <-chan *Result {
 				outputDir := config.DefaultOutputDir
-				config.GetConfig(config.NewMemoryEnvLoader(map[string]string{}))
+				cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
 				expectedErr := &ErrorOutputDirNotCreated{
 					outputDir,
 					ErrorPathExistsButNotDir,
@@ -53,7 +87,7 @@ func TestProceedProjects(t *testing.T) {
 					projectsChan <- &Project{pathWithNamespace: "project1"}
 				}()
 
-				resultChan := proceedProjects(context.Background(), cloner, projectsChan)
+				resultChan := proceedProjects(context.Background(), cfg, cloner, projectsChan, nil, nil)
 				resultDone := false
 
 				for !resultDone {
@@ -77,7 +111,7 @@ func TestProceedProjects(t *testing.T) {
 		{
 			name: "error proxying from clone project process",
 			fn: func(t *testing.T) <-chan *Result {
-				config.GetConfig(config.NewMemoryEnvLoader(map[string]string{}))
+				cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
 				expectedErr := errors.New("clone error")
 
 				cloner := &mockCloner{
@@ -91,7 +125,7 @@ func TestProceedProjects(t *testing.T) {
 					projectsChan <- &Project{pathWithNamespace: "project1"}
 				}()
 
-				resultChan := proceedProjects(context.Background(), cloner, projectsChan)
+				resultChan := proceedProjects(context.Background(), cfg, cloner, projectsChan, nil, nil)
 				resultDone := false
 
 				for !resultDone {
@@ -115,7 +149,7 @@ func TestProceedProjects(t *testing.T) {
 		{
 			name: "clone projects successfully",
 			fn: func(t *testing.T) <-chan *Result {
-				config.GetConfig(config.NewMemoryEnvLoader(map[string]string{}))
+				cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
 
 				cloner := &mockCloner{
 					osWrapper: &mockOSWrapper{},
@@ -135,7 +169,7 @@ func TestProceedProjects(t *testing.T) {
 					}
 				}()
 
-				resultChan := proceedProjects(context.Background(), cloner, projectsChan)
+				resultChan := proceedProjects(context.Background(), cfg, cloner, projectsChan, nil, nil)
 				resultDone := false
 
 				received := map[string]struct{}{}
@@ -173,7 +207,7 @@ func TestProceedProjects(t *testing.T) {
 		{
 			name: "should skip nil projects",
 			fn: func(t *testing.T) <-chan *Result {
-				config.GetConfig(config.NewMemoryEnvLoader(map[string]string{}))
+				cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
 
 				cloner := &mockCloner{
 					osWrapper: &mockOSWrapper{},
@@ -193,7 +227,7 @@ func TestProceedProjects(t *testing.T) {
 					}
 				}()
 
-				resultChan := proceedProjects(context.Background(), cloner, projectsChan)
+				resultChan := proceedProjects(context.Background(), cfg, cloner, projectsChan, nil, nil)
 				resultDone := false
 
 				received := map[string]struct{}{}
@@ -234,7 +268,7 @@ func TestProceedProjects(t *testing.T) {
 		{
 			name: "should handle context cancellation",
 			fn: func(t *testing.T) <-chan *Result {
-				config.GetConfig(config.NewMemoryEnvLoader(map[string]string{}))
+				cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
 
 				cloner := &mockCloner{
 					osWrapper: &mockOSWrapper{},
@@ -249,7 +283,7 @@ func TestProceedProjects(t *testing.T) {
 				ctx, cancel := context.WithCancel(context.Background())
 				cancel() // Cancel the context immediately
 
-				resultChan := proceedProjects(ctx, cloner, projectsChan)
+				resultChan := proceedProjects(ctx, cfg, cloner, projectsChan, nil, nil)
 
 				time.Sleep(50 * time.Millisecond)
 
@@ -282,3 +316,239 @@ func TestProceedProjects(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncProject_StateStore(t *testing.T) {
+	cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
+	lastActivity := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	project := &Project{id: 1, pathWithNamespace: "group/project1", lastActivityAt: &lastActivity}
+
+	store := NewStateStore(filepath.Join(t.TempDir(), "state.json"))
+	cloner := &mockCloner{
+		osWrapper:  &mockOSWrapper{isDirExists: true},
+		refs:       map[string]string{"refs/heads/main": "aaa"},
+		remoteRefs: map[string]string{"refs/heads/main": "aaa"},
+	}
+
+	result := syncProject(context.Background(), cfg, cloner, store, project)
+	if result.err != nil || result.status != SyncStatusCloned {
+		t.Fatalf("expected cloned status, got %s, %v", result.status, result.err)
+	}
+
+	state, ok := store.Get(project.id)
+	if !ok {
+		t.Fatal("expected project state to be recorded")
+	}
+	if state.Refs["refs/heads/main"] != "aaa" || state.LastSuccessAt == nil {
+		t.Errorf("unexpected state recorded: %+v", state)
+	}
+
+	result = syncProject(context.Background(), cfg, cloner, store, project)
+	if result.err != nil || result.status != SyncStatusUnchanged {
+		t.Fatalf("expected unchanged status, got %s, %v", result.status, result.err)
+	}
+	if len(cloner.cloned) != 1 {
+		t.Errorf("expected unchanged project to be skipped, cloned %d times", len(cloner.cloned))
+	}
+
+	cloner.remoteRefs = map[string]string{"refs/heads/main": "bbb"}
+
+	result = syncProject(context.Background(), cfg, cloner, store, project)
+	if result.err != nil || result.status != SyncStatusCloned {
+		t.Fatalf("expected project with new commits to be synced, got %s, %v", result.status, result.err)
+	}
+	if len(cloner.cloned) != 2 {
+		t.Errorf("expected project to be synced again, cloned %d times", len(cloner.cloned))
+	}
+
+	newActivity := lastActivity.Add(time.Hour)
+	project.lastActivityAt = &newActivity
+
+	result = syncProject(context.Background(), cfg, cloner, store, project)
+	if result.err != nil || result.status != SyncStatusCloned {
+		t.Fatalf("expected project with new activity to be synced, got %s, %v", result.status, result.err)
+	}
+	if len(cloner.cloned) != 3 {
+		t.Errorf("expected project to be synced again, cloned %d times", len(cloner.cloned))
+	}
+}
+
+func TestSyncProject_RelocateRenamedProject(t *testing.T) {
+	cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
+	lastActivity := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		osWrapper       *mockOSWrapper
+		expectedMoved   string
+		expectedStatus  SyncStatus
+		expectedCloned  int
+		expectedUpdated int
+	}{
+		{
+			name: "move existing clone and update it",
+			osWrapper: &mockOSWrapper{dirs: map[string]bool{
+				"old-group/project": true,
+				"new-group/project": false,
+			}},
+			expectedMoved:   "old-group/project",
+			expectedStatus:  SyncStatusUpdated,
+			expectedUpdated: 1,
+		},
+		{
+			name: "clone when old location is gone",
+			osWrapper: &mockOSWrapper{dirs: map[string]bool{
+				"old-group/project": false,
+				"new-group/project": false,
+			}},
+			expectedStatus: SyncStatusCloned,
+			expectedCloned: 1,
+		},
+		{
+			name: "keep both when new location is taken",
+			osWrapper: &mockOSWrapper{dirs: map[string]bool{
+				"old-group/project": true,
+				"new-group/project": true,
+			}},
+			expectedStatus: SyncStatusCloned,
+			expectedCloned: 1,
+		},
+		{
+			name: "failed to move",
+			osWrapper: &mockOSWrapper{
+				dirs: map[string]bool{
+					"old-group/project": true,
+					"new-group/project": false,
+				},
+				renameErr: errors.New("cross-device link"),
+			},
+			expectedStatus: SyncStatusFailed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := NewStateStore(filepath.Join(t.TempDir(), "state.json"))
+			store.Record(
+				&Project{id: 1, pathWithNamespace: "old-group/project", lastActivityAt: &lastActivity},
+				"old-group/project",
+				SyncStatusCloned,
+				nil,
+				nil,
+			)
+
+			cloner := &mockCloner{osWrapper: test.osWrapper}
+			project := &Project{id: 1, pathWithNamespace: "new-group/project", lastActivityAt: &lastActivity}
+
+			result := syncProject(context.Background(), cfg, cloner, store, project)
+
+			if result.status != test.expectedStatus {
+				t.Errorf("expected status %s, got %s (%v)", test.expectedStatus, result.status, result.err)
+			}
+			if result.movedFrom != test.expectedMoved {
+				t.Errorf("expected moved from %q, got %q", test.expectedMoved, result.movedFrom)
+			}
+			if test.expectedMoved != "" && test.osWrapper.renamed != [2]string{"old-group/project", "new-group/project"} {
+				t.Errorf("unexpected rename: %v", test.osWrapper.renamed)
+			}
+			if len(cloner.cloned) != test.expectedCloned || len(cloner.updated) != test.expectedUpdated {
+				t.Errorf("expected %d clones and %d updates, got %d and %d",
+					test.expectedCloned, test.expectedUpdated, len(cloner.cloned), len(cloner.updated))
+			}
+		})
+	}
+}
+
+func TestProceedProjects_ResumeFromJournal(t *testing.T) {
+	cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{}))
+
+	journal, err := OpenJournal(filepath.Join(t.TempDir(), "run.journal"), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = journal.Close()
+	}()
+
+	_ = journal.Record(&Result{project: &Project{id: 1}, status: SyncStatusUpdated})
+	_ = journal.Record(&Result{project: &Project{id: 2}, status: SyncStatusFailed, err: errors.New("fail")})
+
+	cloner := &mockCloner{osWrapper: &mockOSWrapper{}}
+
+	projectsChan := make(chan *Project)
+	go func() {
+		defer close(projectsChan)
+		for id := range 3 {
+			projectsChan <- &Project{id: id + 1, pathWithNamespace: fmt.Sprintf("project%d", id+1)}
+		}
+	}()
+
+	statuses := map[int]*Result{}
+	for result := range proceedProjects(context.Background(), cfg, cloner, projectsChan, nil, journal) {
+		statuses[result.project.id] = result
+	}
+
+	if result := statuses[1]; !result.resumed || result.status != SyncStatusUpdated {
+		t.Errorf("expected finished project to be resumed with its status, got %+v", result)
+	}
+	for _, id := range []int{2, 3} {
+		if result := statuses[id]; result.resumed || result.status != SyncStatusCloned {
+			t.Errorf("expected project %d to be cloned, got %+v", id, result)
+		}
+	}
+	if len(cloner.cloned) != 2 {
+		t.Errorf("expected 2 projects to be cloned, got %d", len(cloner.cloned))
+	}
+
+	if _, ok := journal.Completed(2); !ok {
+		t.Error("expected retried project to be recorded in the journal")
+	}
+}
+
+// crossDeviceOSWrapper fails to rename the directory like os.Rename does between filesystems.
+type crossDeviceOSWrapper struct {
+	DefaultOSWrapper
+	from string
+}
+
+func (w *crossDeviceOSWrapper) Rename(oldPath, newPath string) error {
+	if oldPath == w.from {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: syscall.EXDEV}
+	}
+	return w.DefaultOSWrapper.Rename(oldPath, newPath)
+}
+
+func TestMoveDir_CrossDevice(t *testing.T) {
+	root := t.TempDir()
+	from := filepath.Join(root, "out", "group", "repo")
+	to := filepath.Join(root, "cold", "group", "repo")
+	stagingDir := filepath.Join(root, "cold", ".staging", "1-group_repo")
+
+	if err := os.MkdirAll(filepath.Join(from, "refs", "heads"), 0o755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(from, "HEAD"), []byte("ref: refs/heads/main\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.Symlink("HEAD", filepath.Join(from, "link")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := moveDir(&crossDeviceOSWrapper{from: from}, from, to, stagingDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if content, err := os.ReadFile(filepath.Join(to, "link")); err != nil || string(content) != "ref: refs/heads/main\n" {
+		t.Errorf("expected the copied repository, got %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(to, "refs", "heads")); err != nil {
+		t.Errorf("expected the copied directories: %v", err)
+	}
+	for _, path := range []string{from, stagingDir} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", path, err)
+		}
+	}
+}
@@ -7,13 +7,15 @@ type ProgressCounter struct {
 	completed uint32
 	success   uint32
 	failed    uint32
+	statuses  map[SyncStatus]uint32
 	mutex     *sync.Mutex
 }
 
 func NewProgressCounter(total uint32) *ProgressCounter {
 	counter := &ProgressCounter{
-		total: total,
-		mutex: &sync.Mutex{},
+		total:    total,
+		statuses: map[SyncStatus]uint32{},
+		mutex:    &sync.Mutex{},
 	}
 
 	return counter
@@ -31,6 +33,23 @@ func (c *ProgressCounter) Update(success bool) {
 	}
 }
 
+// UpdateStatus counts the project by its sync status, failed status is counted as an error.
+func (c *ProgressCounter) UpdateStatus(status SyncStatus) {
+	c.Update(status != SyncStatusFailed)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.statuses[status]++
+}
+
+func (c *ProgressCounter) GetStatusCount(status SyncStatus) uint32 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.statuses[status]
+}
+
 func (c *ProgressCounter) GetStats() (uint32, uint32, uint32, uint32) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
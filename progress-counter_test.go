@@ -38,3 +38,35 @@ func TestProgressCounter(t *testing.T) {
 		t.Fatalf("Test failed: expected %d errors, got %d", halfTotal, errors)
 	}
 }
+
+func TestProgressCounter_UpdateStatus(t *testing.T) {
+	counter := NewProgressCounter(0)
+
+	statuses := []SyncStatus{
+		SyncStatusCloned,
+		SyncStatusUpdated,
+		SyncStatusUpdated,
+		SyncStatusUnchanged,
+		SyncStatusFailed,
+	}
+	for _, status := range statuses {
+		counter.UpdateStatus(status)
+	}
+
+	_, completed, success, failed := counter.GetStats()
+	if completed != 5 || success != 4 || failed != 1 {
+		t.Fatalf("unexpected stats: completed %d, success %d, failed %d", completed, success, failed)
+	}
+
+	expected := map[SyncStatus]uint32{
+		SyncStatusCloned:    1,
+		SyncStatusUpdated:   2,
+		SyncStatusUnchanged: 1,
+		SyncStatusFailed:    1,
+	}
+	for status, count := range expected {
+		if got := counter.GetStatusCount(status); got != count {
+			t.Errorf("expected %d projects with status %s, got %d", count, status, got)
+		}
+	}
+}
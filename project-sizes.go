@@ -0,0 +1,141 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// fetchProjectSizes fetches the statistics of every project of unknown repository size with maxWorkers
+// concurrent requests, only lists of all, member and user projects come with statistics. Projects the filter excludes anyway are passed
+// without a request. Statistics need at least the reporter role, projects whose statistics can't be fetched
+// are passed with an unknown size.
+func fetchProjectSizes(
+	ctx context.Context,
+	client ProjectsService,
+	filter *projectFilter,
+	maxWorkers int,
+	projectsChan <-chan *Project,
+) <-chan *Project {
+	dataChan := make(chan *Project)
+
+	go func() {
+		defer close(dataChan)
+
+		wg := &sync.WaitGroup{}
+		wg.Add(maxWorkers)
+
+		for range maxWorkers {
+			go func() {
+				defer wg.Done()
+
+				for project := range projectsChan {
+					if project == nil {
+						continue
+					}
+
+					if project.repositorySize == nil && filter.exclusionReason(project) == "" {
+						fetchProjectSize(ctx, client, project)
+					}
+
+					select {
+					case <-ctx.Done():
+						return
+					case dataChan <- project:
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+	}()
+
+	return dataChan
+}
+
+// fetchProjectSize sets the repository size of the project from its statistics.
+func fetchProjectSize(ctx context.Context, client ProjectsService, project *Project) {
+	statistics := true
+	fetched, _, err := client.GetProject(project.id, &gitlab.GetProjectOptions{Statistics: &statistics}, gitlab.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to fetch the size of project %s: %v\n", project.pathWithNamespace, err)
+		}
+		return
+	}
+
+	if fetched != nil && fetched.Statistics != nil {
+		size := fetched.Statistics.RepositorySize
+		project.repositorySize = &size
+	}
+}
+
+// queueBySize holds the projects back until all are discovered, prints their total repository size
+// and passes them in the clone order. Projects of unknown size come last.
+func queueBySize(ctx context.Context, order config.CloneOrder, projectsChan <-chan *Project) <-chan *Project {
+	dataChan := make(chan *Project)
+
+	go func() {
+		defer close(dataChan)
+
+		var projects []*Project
+		var total int64
+		unknown := 0
+
+		for project := range projectsChan {
+			if project == nil {
+				continue
+			}
+
+			projects = append(projects, project)
+			if project.repositorySize == nil {
+				unknown++
+				continue
+			}
+			total += *project.repositorySize
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("Projects to sync: %d, total repository size: %s, unknown size: %d\n", len(projects), formatSize(total), unknown)
+
+		if order != config.CloneOrderDiscovery {
+			slices.SortStableFunc(projects, func(a, b *Project) int {
+				return compareProjectSizes(a, b, order)
+			})
+		}
+
+		for _, project := range projects {
+			select {
+			case <-ctx.Done():
+				return
+			case dataChan <- project:
+			}
+		}
+	}()
+
+	return dataChan
+}
+
+// compareProjectSizes orders projects by repository size in the clone order, unknown sizes last.
+func compareProjectSizes(a, b *Project, order config.CloneOrder) int {
+	switch {
+	case a.repositorySize == nil && b.repositorySize == nil:
+		return 0
+	case a.repositorySize == nil:
+		return 1
+	case b.repositorySize == nil:
+		return -1
+	case order == config.CloneOrderLargest:
+		return cmp.Compare(*b.repositorySize, *a.repositorySize)
+	default:
+		return cmp.Compare(*a.repositorySize, *b.repositorySize)
+	}
+}
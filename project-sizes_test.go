@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func sizeOf(size int64) *int64 {
+	return &size
+}
+
+func TestFetchProjectSizes(t *testing.T) {
+	client := &FakeGitlabProjects{
+		byIDs: map[string]*gitlab.Project{
+			"1": {ID: 1, Statistics: &gitlab.Statistics{RepositorySize: 2048}},
+			"2": {ID: 2},
+			"3": {ID: 3, Statistics: &gitlab.Statistics{RepositorySize: 4096}},
+		},
+	}
+	filter := newProjectFilter(config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+		config.ExcludePathsKey: "org/excluded",
+	})))
+
+	projectsChan := make(chan *Project)
+	go func() {
+		defer close(projectsChan)
+
+		projectsChan <- &Project{id: 1, pathWithNamespace: "org/app"}
+		projectsChan <- &Project{id: 2, pathWithNamespace: "org/guest"}
+		projectsChan <- &Project{id: 3, pathWithNamespace: "org/excluded"}
+		projectsChan <- &Project{id: 4, pathWithNamespace: "org/missing"}
+		projectsChan <- &Project{id: 5, pathWithNamespace: "org/listed", repositorySize: sizeOf(10)}
+	}()
+
+	sizes := map[string]int64{}
+	for project := range fetchProjectSizes(context.Background(), client, filter, 2, projectsChan) {
+		if project.repositorySize != nil {
+			sizes[project.pathWithNamespace] = *project.repositorySize
+		} else {
+			sizes[project.pathWithNamespace] = -1
+		}
+	}
+
+	expected := map[string]int64{"org/app": 2048, "org/guest": -1, "org/excluded": -1, "org/missing": -1, "org/listed": 10}
+	if len(sizes) != len(expected) {
+		t.Errorf("expected sizes %v, got %v", expected, sizes)
+	}
+	for path, size := range expected {
+		if sizes[path] != size {
+			t.Errorf("expected size of %s %d, got %d", path, size, sizes[path])
+		}
+	}
+}
+
+func TestQueueBySize(t *testing.T) {
+	tests := []struct {
+		order    config.CloneOrder
+		expected []string
+	}{
+		{config.CloneOrderDiscovery, []string{"medium", "unknown", "large", "small"}},
+		{config.CloneOrderSmallest, []string{"small", "medium", "large", "unknown"}},
+		{config.CloneOrderLargest, []string{"large", "medium", "small", "unknown"}},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.order), func(t *testing.T) {
+			projectsChan := make(chan *Project)
+			go func() {
+				defer close(projectsChan)
+
+				projectsChan <- &Project{pathWithNamespace: "medium", repositorySize: sizeOf(200)}
+				projectsChan <- &Project{pathWithNamespace: "unknown"}
+				projectsChan <- &Project{pathWithNamespace: "large", repositorySize: sizeOf(3000)}
+				projectsChan <- &Project{pathWithNamespace: "small", repositorySize: sizeOf(10)}
+			}()
+
+			var order []string
+			for project := range queueBySize(context.Background(), test.order, projectsChan) {
+				order = append(order, project.pathWithNamespace)
+			}
+
+			if !slices.Equal(order, test.expected) {
+				t.Errorf("expected order %v, got %v", test.expected, order)
+			}
+		})
+	}
+}
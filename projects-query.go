@@ -0,0 +1,60 @@
+package main
+
+import (
+	"time"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// projectsQuery holds the project filters GitLab applies to project lists itself, which reduces the traffic.
+// GitLab takes a single visibility level and requires all given topics, so only single include values are passed,
+// the lists are filtered by filterProjects anyway.
+type projectsQuery struct {
+	activeSince time.Time
+	visibility  *gitlab.VisibilityValue
+	topic       *string
+	// statistics requests the repository sizes with the lists, which don't narrow them down.
+	statistics bool
+}
+
+// newProjectsQuery returns the query of the project filters. It has no filters when local repositories are pruned,
+// as the projects GitLab leaves out of the lists would be taken for deleted ones.
+func newProjectsQuery(cfg *config.Config) *projectsQuery {
+	query := &projectsQuery{statistics: cfg.GetStatistics()}
+	if cfg.GetPruneMode() != config.PruneModeOff {
+		return query
+	}
+
+	query.activeSince = cfg.GetActiveSince()
+
+	if visibilities := cfg.GetIncludeVisibility(); len(visibilities) == 1 {
+		visibility := gitlab.VisibilityValue(visibilities[0])
+		query.visibility = &visibility
+	}
+
+	if topics := cfg.GetIncludeTopics(); len(topics) == 1 {
+		query.topic = &topics[0]
+	}
+
+	return query
+}
+
+// applyToProjects sets the query on the options of the lists of all, member and user projects.
+func (q *projectsQuery) applyToProjects(opt *gitlab.ListProjectsOptions) {
+	if !q.activeSince.IsZero() {
+		opt.LastActivityAfter = &q.activeSince
+	}
+	opt.Visibility = q.visibility
+	opt.Topic = q.topic
+	if q.statistics {
+		opt.Statistics = &q.statistics
+	}
+}
+
+// applyToGroupProjects sets the query on the options of the lists of group projects,
+// which can't be filtered by the activity and don't return statistics.
+func (q *projectsQuery) applyToGroupProjects(opt *gitlab.ListGroupProjectsOptions) {
+	opt.Visibility = q.visibility
+	opt.Topic = q.topic
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestNewProjectsQuery(t *testing.T) {
+	tests := []struct {
+		name               string
+		envs               map[string]string
+		expectedActivity   bool
+		expectedVisibility string
+		expectedTopic      string
+		expectedStatistics bool
+	}{
+		{
+			name: "no filters",
+		},
+		{
+			name: "single values",
+			envs: map[string]string{
+				config.ActiveSinceKey:       "2026-01-01",
+				config.IncludeVisibilityKey: "private",
+				config.IncludeTopicsKey:     "pci",
+			},
+			expectedActivity:   true,
+			expectedVisibility: "private",
+			expectedTopic:      "pci",
+		},
+		{
+			name: "several values",
+			envs: map[string]string{
+				config.IncludeVisibilityKey: "private,internal",
+				config.IncludeTopicsKey:     "pci,production",
+			},
+		},
+		{
+			name: "exclude values",
+			envs: map[string]string{
+				config.ExcludeVisibilityKey: "public",
+				config.ExcludeTopicsKey:     "legacy",
+			},
+		},
+		{
+			name: "pruning",
+			envs: map[string]string{
+				config.PruneModeKey:         "quarantine",
+				config.ActiveSinceKey:       "2026-01-01",
+				config.IncludeVisibilityKey: "private",
+				config.IncludeTopicsKey:     "pci",
+			},
+		},
+		{
+			name:               "statistics",
+			envs:               map[string]string{config.MaxRepoSizeKey: "1GB"},
+			expectedStatistics: true,
+		},
+		{
+			name: "statistics with pruning",
+			envs: map[string]string{
+				config.PruneModeKey:  "quarantine",
+				config.StatisticsKey: "true",
+			},
+			expectedStatistics: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query := newProjectsQuery(config.NewConfig(config.NewMemoryEnvLoader(test.envs)))
+
+			opt := &gitlab.ListProjectsOptions{}
+			query.applyToProjects(opt)
+
+			if (opt.LastActivityAfter != nil) != test.expectedActivity {
+				t.Errorf("expected last activity filter %t, got %v", test.expectedActivity, opt.LastActivityAfter)
+			}
+
+			visibility := ""
+			if opt.Visibility != nil {
+				visibility = string(*opt.Visibility)
+			}
+			if visibility != test.expectedVisibility {
+				t.Errorf("expected visibility %q, got %q", test.expectedVisibility, visibility)
+			}
+
+			topic := ""
+			if opt.Topic != nil {
+				topic = *opt.Topic
+			}
+			if topic != test.expectedTopic {
+				t.Errorf("expected topic %q, got %q", test.expectedTopic, topic)
+			}
+
+			if statistics := opt.Statistics != nil && *opt.Statistics; statistics != test.expectedStatistics {
+				t.Errorf("expected statistics %t, got %t", test.expectedStatistics, statistics)
+			}
+		})
+	}
+}
+
+func TestProjectsQuery_ApplyToGroupProjects(t *testing.T) {
+	visibility := gitlab.InternalVisibility
+	topic := "production"
+	query := &projectsQuery{
+		activeSince: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		visibility:  &visibility,
+		topic:       &topic,
+	}
+
+	opt := &gitlab.ListGroupProjectsOptions{}
+	query.applyToGroupProjects(opt)
+
+	if opt.Visibility == nil || *opt.Visibility != gitlab.InternalVisibility {
+		t.Errorf("expected visibility %s, got %v", gitlab.InternalVisibility, opt.Visibility)
+	}
+	if opt.Topic == nil || *opt.Topic != topic {
+		t.Errorf("expected topic %s, got %v", topic, opt.Topic)
+	}
+}
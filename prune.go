@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+)
+
+// quarantineTimeLayout is the name layout of a quarantine batch directory.
+const quarantineTimeLayout = "20060102T150405Z"
+
+// isGitRepository reports whether the directory is a working copy or a bare repository.
+func isGitRepository(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true
+	}
+
+	head, err := os.Stat(filepath.Join(dir, "HEAD"))
+	if err != nil || head.IsDir() {
+		return false
+	}
+
+	for _, name := range []string{"objects", "refs"} {
+		stat, err := os.Stat(filepath.Join(dir, name))
+		if err != nil || !stat.IsDir() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// findLocalRepositories returns the paths of git repositories under the root directory,
+// the excluded directories and the content of found repositories are not scanned.
+func findLocalRepositories(root string, excludeDirs ...string) ([]string, error) {
+	excluded := make([]string, 0, len(excludeDirs))
+	for _, dir := range excludeDirs {
+		excluded = append(excluded, filepath.Clean(dir))
+	}
+
+	var repositories []string
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipAll
+			}
+			return err
+		}
+
+		if !entry.IsDir() {
+			return nil
+		}
+
+		if slices.Contains(excluded, filepath.Clean(path)) {
+			return filepath.SkipDir
+		}
+
+		if path != root && isGitRepository(path) {
+			repositories = append(repositories, filepath.Clean(path))
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+
+	return repositories, err
+}
+
+// findOrphanedRepositories returns local repositories which do not belong to any of the discovered project directories.
+func findOrphanedRepositories(cfg *config.Config, discovered map[string]struct{}) ([]string, error) {
+	root := cfg.GetOutputDir()
+	if root == "" {
+		root = "."
+	}
+
+	repositories, err := findLocalRepositories(root, append(cfg.GetStagingDirs(), cfg.GetQuarantineDir())...)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []string
+	for _, repository := range repositories {
+		if _, ok := discovered[repository]; !ok {
+			orphans = append(orphans, repository)
+		}
+	}
+
+	return orphans, nil
+}
+
+// pruneRepositories moves the orphaned repositories to a new batch in the quarantine directory,
+// or deletes them in the delete mode. It returns the repositories that were pruned.
+// Nothing is pruned without an explicit output directory, as the working directory may hold anything.
+// Forks borrowing objects of an orphan are dissociated first, an orphan whose forks can't be dissociated is kept.
+func pruneRepositories(ctx context.Context, osWrapper OSWrapper, cfg *config.Config, orphans []string, now time.Time) ([]string, error) {
+	mode := cfg.GetPruneMode()
+	if mode != config.PruneModeQuarantine && mode != config.PruneModeDelete {
+		return nil, nil
+	}
+
+	root := cfg.GetOutputDir()
+	if root == "" {
+		return nil, config.ErrorPruneWithoutOutputDir
+	}
+	batchDir := filepath.Join(cfg.GetQuarantineDir(), now.UTC().Format(quarantineTimeLayout))
+
+	references, err := findForkReferences(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if mode == config.PruneModeDelete {
+		// Deleted forks don't need the objects.
+		for _, orphan := range orphans {
+			delete(references, orphan)
+		}
+	}
+
+	var pruned []string
+	var errs []error
+
+	for _, orphan := range orphans {
+		if err := dissociateForks(ctx, osWrapper, references, orphan); err != nil {
+			errs = append(errs, &ErrorPruneRepository{orphan, err})
+			continue
+		}
+
+		if mode == config.PruneModeDelete {
+			if err := osWrapper.RemoveAll(orphan); err != nil {
+				errs = append(errs, &ErrorPruneRepository{orphan, err})
+				continue
+			}
+			pruned = append(pruned, orphan)
+			continue
+		}
+
+		relPath, err := filepath.Rel(filepath.Join(root, "."), orphan)
+		if err != nil {
+			errs = append(errs, &ErrorPruneRepository{orphan, err})
+			continue
+		}
+
+		target := filepath.Join(batchDir, relPath)
+		if err := osWrapper.MakeDirAll(filepath.Dir(target)); err != nil {
+			errs = append(errs, &ErrorPruneRepository{orphan, err})
+			continue
+		}
+
+		if err := osWrapper.Rename(orphan, target); err != nil {
+			errs = append(errs, &ErrorPruneRepository{orphan, err})
+			continue
+		}
+
+		pruned = append(pruned, orphan)
+	}
+
+	return pruned, errors.Join(errs...)
+}
+
+// purgeQuarantine deletes quarantine batches older than the retention period and returns their paths.
+func purgeQuarantine(osWrapper OSWrapper, cfg *config.Config, now time.Time) ([]string, error) {
+	quarantineDir := cfg.GetQuarantineDir()
+
+	entries, err := os.ReadDir(quarantineDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var purged []string
+	var errs []error
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		batchTime, err := time.Parse(quarantineTimeLayout, entry.Name())
+		if err != nil || now.Sub(batchTime) < cfg.GetQuarantineRetention() {
+			continue
+		}
+
+		batchDir := filepath.Join(quarantineDir, entry.Name())
+		if err := osWrapper.RemoveAll(batchDir); err != nil {
+			errs = append(errs, &ErrorPruneRepository{batchDir, err})
+			continue
+		}
+
+		purged = append(purged, batchDir)
+	}
+
+	return purged, errors.Join(errs...)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/artzub/gitlab-repo-extractor/config"
+)
+
+func makeBareRepository(t *testing.T, dir string) {
+	t.Helper()
+
+	for _, name := range []string{"objects", "refs"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "HEAD"), []byte("ref: refs/heads/main\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func makeWorkingRepository(t *testing.T, dir string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0o755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFindLocalRepositories(t *testing.T) {
+	root := t.TempDir()
+
+	makeBareRepository(t, filepath.Join(root, "group", "bare"))
+	makeWorkingRepository(t, filepath.Join(root, "group", "sub", "working"))
+	makeBareRepository(t, filepath.Join(root, ".quarantine", "batch", "group", "old"))
+	if err := os.MkdirAll(filepath.Join(root, "group", "empty"), 0o755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repositories, err := findLocalRepositories(root, filepath.Join(root, ".quarantine"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(root, "group", "bare"),
+		filepath.Join(root, "group", "sub", "working"),
+	}
+	if !slices.Equal(repositories, expected) {
+		t.Errorf("expected %v, got %v", expected, repositories)
+	}
+
+	repositories, err = findLocalRepositories(filepath.Join(root, "missing"))
+	if err != nil || len(repositories) != 0 {
+		t.Errorf("expected no repositories for missing root, got %v, %v", repositories, err)
+	}
+}
+
+func TestPruneRepositories(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		mode         string
+		expectPruned bool
+		expectMoved  bool
+	}{
+		{"dry run keeps repositories", "dry-run", false, false},
+		{"quarantine moves repositories", "quarantine", true, true},
+		{"delete removes repositories", "delete", true, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			root := t.TempDir()
+			cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+				config.OutputDirKey:         root,
+				config.PruneModeKey:         test.mode,
+				config.ArchivedOutputDirKey: "archived",
+			}))
+
+			kept := filepath.Join(root, "group", "kept")
+			orphan := filepath.Join(root, "group", "orphan")
+			makeBareRepository(t, kept)
+			makeBareRepository(t, orphan)
+			makeBareRepository(t, filepath.Join(cfg.GetStagingDir(), "1-group_unfinished"))
+			makeBareRepository(t, filepath.Join(cfg.GetArchivedStagingDir(), "2-group_unfinished"))
+
+			orphans, err := findOrphanedRepositories(cfg, map[string]struct{}{kept: {}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(orphans, []string{orphan}) {
+				t.Fatalf("expected orphans %v, got %v", []string{orphan}, orphans)
+			}
+
+			pruned, err := pruneRepositories(context.Background(), GetDefaultOSWrapper(), cfg, orphans, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.expectPruned != (len(pruned) == 1) {
+				t.Errorf("unexpected pruned repositories: %v", pruned)
+			}
+
+			if _, err := os.Stat(orphan); test.expectPruned != os.IsNotExist(err) {
+				t.Errorf("unexpected state of orphaned repository: %v", err)
+			}
+
+			moved := filepath.Join(cfg.GetQuarantineDir(), now.Format(quarantineTimeLayout), "group", "orphan")
+			if _, err := os.Stat(moved); test.expectMoved != (err == nil) {
+				t.Errorf("unexpected state of quarantined repository: %v", err)
+			}
+
+			if _, err := os.Stat(kept); err != nil {
+				t.Errorf("expected discovered repository to be kept: %v", err)
+			}
+		})
+	}
+}
+
+func TestPruneRepositories_WithoutOutputDir(t *testing.T) {
+	for _, mode := range []string{"quarantine", "delete"} {
+		t.Run(mode, func(t *testing.T) {
+			cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+				config.PruneModeKey: mode,
+			}))
+			osWrapper := &mockOSWrapper{}
+
+			pruned, err := pruneRepositories(context.Background(), osWrapper, cfg, []string{"group/orphan"}, time.Now())
+			if !errors.Is(err, config.ErrorPruneWithoutOutputDir) {
+				t.Errorf("expected %v, got %v", config.ErrorPruneWithoutOutputDir, err)
+			}
+			if len(pruned) > 0 || osWrapper.removedDir != "" || osWrapper.renamed[0] != "" {
+				t.Errorf("expected nothing to be pruned, got %v", pruned)
+			}
+		})
+	}
+}
+
+func TestPurgeQuarantine(t *testing.T) {
+	root := t.TempDir()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cfg := config.NewConfig(config.NewMemoryEnvLoader(map[string]string{
+		config.OutputDirKey:           root,
+		config.QuarantineRetentionKey: "7",
+	}))
+
+	expired := filepath.Join(cfg.GetQuarantineDir(), now.AddDate(0, 0, -8).Format(quarantineTimeLayout))
+	recent := filepath.Join(cfg.GetQuarantineDir(), now.AddDate(0, 0, -1).Format(quarantineTimeLayout))
+	unknown := filepath.Join(cfg.GetQuarantineDir(), "keep-me")
+	for _, dir := range []string{expired, recent, unknown} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	purged, err := purgeQuarantine(GetDefaultOSWrapper(), cfg, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(purged, []string{expired}) {
+		t.Errorf("expected %v to be purged, got %v", []string{expired}, purged)
+	}
+
+	for _, dir := range []string{recent, unknown} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("expected %s to be kept: %v", dir, err)
+		}
+	}
+}
@@ -2,18 +2,128 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/artzub/gitlab-repo-extractor/config"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
 
+// stateSaveInterval is how often the state is persisted during a run.
+const stateSaveInterval = time.Minute
+
+// instanceRun is the outcome of processing an instance.
+type instanceRun struct {
+	name    string
+	summary *RunSummary
+	list    *ProjectList
+	err     error
+}
+
+// run processes every configured instance one after another.
 func run() error {
-	ctx := context.Background()
+	ctx, stop := withShutdownSignals(context.Background(), os.Exit)
+	defer stop()
 
 	cfg := config.GetConfig()
+	instances := cfg.GetInstances()
+
+	var runs []*instanceRun
+	for _, instance := range instances {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if instance.GetInstanceName() != "" {
+			log.Println("Instance:", instance.GetInstanceName())
+		}
+
+		report := &instanceRun{name: instance.GetInstanceName()}
+		report.err = runInstance(ctx, instance, report)
+		runs = append(runs, report)
+
+		if len(instances) > 1 {
+			log.Println()
+		}
+	}
+
+	if cfg.GetDryRun() {
+		lists := make([]*ProjectList, 0, len(runs))
+		for _, report := range runs {
+			if report.list != nil {
+				lists = append(lists, report.list)
+			}
+		}
+
+		if err := writeProjectLists(os.Stdout, cfg.GetListFormat(), lists); err != nil {
+			return err
+		}
+	}
+
+	if len(instances) > 1 && !cfg.GetDryRun() {
+		logInstancesReport(runs)
+	}
+
+	return joinInstanceErrors(runs, len(instances))
+}
+
+// joinInstanceErrors returns the errors of the runs, wrapped with the instance name when several instances
+// are configured. Instances left out by an interruption are reported as ErrorInterrupted.
+func joinInstanceErrors(runs []*instanceRun, instances int) error {
+	if instances == 1 && len(runs) == 1 {
+		return runs[0].err
+	}
+
+	var errs []error
+	for _, report := range runs {
+		if report.err != nil {
+			errs = append(errs, &ErrorInstanceRun{report.name, report.err})
+		}
+	}
+
+	err := errors.Join(errs...)
+	if len(runs) < instances && !errors.Is(err, ErrorInterrupted) {
+		return errors.Join(err, ErrorInterrupted)
+	}
+
+	return err
+}
+
+// logInstancesReport prints the statistics of every instance and their totals.
+func logInstancesReport(runs []*instanceRun) {
+	total := &RunSummary{}
+
+	log.Println("Instances:")
+	for _, report := range runs {
+		summary := report.summary
+		if summary == nil {
+			log.Printf("  %s: %v\n", report.name, report.err)
+			continue
+		}
+
+		log.Printf("  %s: cloned %d, updated %d, unchanged %d, failed %d, fetch errors %d, API requests %d\n",
+			report.name, summary.Cloned, summary.Updated, summary.Unchanged, summary.Failed, summary.FetchErrors, summary.APIRequests)
+
+		total.Cloned += summary.Cloned
+		total.Updated += summary.Updated
+		total.Unchanged += summary.Unchanged
+		total.Failed += summary.Failed
+		total.FetchErrors += summary.FetchErrors
+		total.APIRequests += summary.APIRequests
+	}
+
+	log.Printf("Total: cloned %d, updated %d, unchanged %d, failed %d, fetch errors %d, API requests %d\n",
+		total.Cloned, total.Updated, total.Unchanged, total.Failed, total.FetchErrors, total.APIRequests)
+}
+
+// runInstance backs up a single GitLab instance and fills the report with its statistics or, in a dry run, its project list.
+func runInstance(ctx context.Context, cfg *config.Config, report *instanceRun) error {
+	startedAt := time.Now().UTC()
 
 	client, errClient := gitlab.NewClient(cfg.GetAccessToken(), gitlab.WithBaseURL(cfg.GetGitLabURL()))
 	if errClient != nil {
@@ -24,28 +134,101 @@ func run() error {
 	log.Println("Output directory:", cfg.GetOutputDir())
 	log.Println("Group IDs:", strings.Join(cfg.GetGroupIDs(), ","))
 	log.Println("Skip Group IDs:", strings.Join(cfg.GetSkipGroupIDs(), ","))
+	log.Println("User IDs:", strings.Join(cfg.GetUserIDs(), ","))
+	log.Println("Admin mode:", cfg.GetAdminMode())
+	log.Println("Include paths:", cfg.GetIncludePaths())
+	log.Println("Exclude paths:", cfg.GetExcludePaths())
+	log.Println("Include visibility:", cfg.GetIncludeVisibility())
+	log.Println("Exclude visibility:", cfg.GetExcludeVisibility())
+	log.Println("Include topics:", strings.Join(cfg.GetIncludeTopics(), ","))
+	log.Println("Exclude topics:", strings.Join(cfg.GetExcludeTopics(), ","))
+	log.Println("Archived projects:", cfg.GetArchived())
+	log.Println("Forks:", cfg.GetForks())
+	if cfg.GetMaxRepoSize() > 0 {
+		log.Println("Max repository size:", formatSize(cfg.GetMaxRepoSize()))
+	}
+	log.Println("Clone order:", cfg.GetCloneOrder())
+	if !cfg.GetActiveSince().IsZero() {
+		log.Println("Active since:", cfg.GetActiveSince().Format(time.RFC3339))
+	}
+	if cfg.GetArchivedOutputDir() != "" {
+		log.Println("Archived output directory:", cfg.GetArchivedOutputDir())
+	}
 	log.Println("Using SSH:", cfg.GetUseSSH())
+	log.Println("Clone mode:", cfg.GetCloneMode())
+	log.Println("Sync existing:", cfg.GetSyncExisting())
+	log.Println("Prune mode:", cfg.GetPruneMode())
+	log.Println("Staging directory:", cfg.GetStagingDir())
+	log.Println("API backend:", cfg.GetAPIBackend())
+	log.Println("Discovery strategy:", cfg.GetDiscoveryStrategy())
 	log.Println("Max workers:", cfg.GetMaxWorkers())
 	log.Println("Max retries:", cfg.GetMaxRetries())
-	log.Println()
 
 	gitlabClient := NewGitlab(client)
 
-	groupsChan, groupErrsChan := fetchGroups(ctx, gitlabClient, cfg)
-	groupsChans := teeChan(ctx, groupsChan, 2)
+	var discoveryClient DiscoveryService = gitlabClient
+	if cfg.GetAPIBackend() == config.APIBackendGraphQL {
+		graphQLClient := NewGraphQLGitlab(gitlabClient)
+		// GraphQL doesn't tell the upstream projects of forks.
+		graphQLClient.restProjects = cfg.GetForks() != config.ForksFull
+		discoveryClient = graphQLClient
+	}
 
-	projectsChan, projectErrsChan := proceedGroups(ctx, gitlabClient, groupsChans[0])
-	projectsChans := teeChan(ctx, projectsChan, 2)
+	if cfg.GetDryRun() {
+		log.Println("Dry run, nothing is cloned")
+		log.Println()
+
+		list, err := collectProjectList(ctx, cfg, discoveryClient)
+		log.Println("API requests:", gitlabClient.GetRequests())
+
+		list.Instance = cfg.GetInstanceName()
+		report.list = list
+
+		return err
+	}
+
+	store := NewStateStore(cfg.GetStateFile())
+	if err := store.Load(); err != nil {
+		log.Println("Starting with an empty state:", err)
+	}
+	log.Println("State file:", store.GetPath())
 
-	jobsChan := proceedProjects(ctx, NewGitCloner(), projectsChans[0])
+	journal, err := OpenJournal(cfg.GetJournalFile(), cfg.GetResume())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := journal.Close(); err != nil {
+			log.Println(err)
+		}
+	}()
+	log.Println("Journal:", journal.GetPath())
+	log.Println("Resume:", cfg.GetResume())
+	log.Println("Run ID:", journal.GetRunID())
+	log.Println()
 
-	errGroup := mergeChans(ctx, groupErrsChan, projectErrsChan)
+	cloner := NewGitCloner()
+
+	// Staging directories left by a crashed or killed run hold incomplete clones.
+	for _, stagingDir := range cfg.GetStagingDirs() {
+		if err := cleanStagingDir(cloner.GetOSWrapper(), stagingDir); err != nil {
+			log.Println("Failed to clean the staging directory:", err)
+		}
+	}
+
+	groupsChan, discoveredChan, errGroup := discoverProjects(ctx, cfg, discoveryClient, newSkipGroupsResolver(discoveryClient, cfg.GetSkipGroupIDs()))
+	projectsChan, excludedChan := selectProjects(ctx, cfg, discoveryClient, discoveredChan)
+	projectsChans := teeChan(ctx, projectsChan, 2)
+
+	jobsChan := proceedProjects(ctx, cfg, cloner, projectsChans[0], store, journal)
 
 	counter := NewProgressCounter(0)
 	errorsCounter := NewProgressCounter(0)
+	resumedCounter := NewProgressCounter(0)
+	discovered := map[string]struct{}{}
 
 	go func() {
-		for group := range groupsChans[1] {
+		for group := range groupsChan {
 			log.Printf("Fetching projects of group: %s\n", group.fullPath)
 		}
 	}()
@@ -56,8 +239,24 @@ func run() error {
 		}
 	}()
 
-	for jobsChan != nil || errGroup != nil {
+	saveTicker := time.NewTicker(stateSaveInterval)
+	defer saveTicker.Stop()
+
+	for jobsChan != nil || errGroup != nil || excludedChan != nil {
 		select {
+		case <-saveTicker.C:
+			if err := store.Save(); err != nil {
+				log.Println(err)
+			}
+		case excluded, ok := <-excludedChan:
+			if !ok {
+				excludedChan = nil
+				continue
+			}
+
+			// An excluded project still exists on GitLab, its local copy is not orphaned.
+			discovered[filepath.Clean(getProjectDir(cfg, excluded.project))] = struct{}{}
+			log.Printf("Excluded project: %s (%s)\n", excluded.project.pathWithNamespace, excluded.reason)
 		case result, ok := <-jobsChan:
 			if !ok {
 				jobsChan = nil
@@ -67,8 +266,12 @@ func run() error {
 				continue
 			}
 
+			if result.project != nil {
+				discovered[filepath.Clean(getProjectDir(cfg, result.project))] = struct{}{}
+			}
+
 			if result.err != nil {
-				counter.Update(false)
+				counter.UpdateStatus(SyncStatusFailed)
 				projectPath := "unknown"
 				if result.project != nil {
 					projectPath = result.project.pathWithNamespace
@@ -77,9 +280,27 @@ func run() error {
 				continue
 			}
 
-			log.Printf("Successfully cloned project: %s\n", result.project.pathWithNamespace)
+			if result.resumed {
+				log.Printf("Already finished in a previous attempt: %s (%s)\n", result.project.pathWithNamespace, result.status)
+				resumedCounter.Update(true)
+				counter.UpdateStatus(result.status)
+				continue
+			}
+
+			if result.movedFrom != "" {
+				log.Printf("Moved project: %s -> %s\n", result.movedFrom, result.project.pathWithNamespace)
+			}
+
+			switch result.status {
+			case SyncStatusUpdated:
+				log.Printf("Successfully updated project: %s\n", result.project.pathWithNamespace)
+			case SyncStatusUnchanged:
+				log.Printf("Project is up to date: %s\n", result.project.pathWithNamespace)
+			default:
+				log.Printf("Successfully cloned project: %s\n", result.project.pathWithNamespace)
+			}
 
-			counter.Update(true)
+			counter.UpdateStatus(result.status)
 		case err, ok := <-errGroup:
 			if !ok {
 				errGroup = nil
@@ -89,21 +310,113 @@ func run() error {
 				errorsCounter.Update(false)
 				log.Println(err)
 			}
-		case <-ctx.Done():
-			break
 		}
 	}
 
-	log.Println("Processing completed.")
+	interrupted := ctx.Err() != nil
+	if interrupted {
+		log.Println("Run interrupted, unfinished clones were removed")
+	} else if err := journal.Finish(); err != nil {
+		log.Println(err)
+	}
+
+	if cfg.GetPruneMode() != config.PruneModeOff && !interrupted {
+		runPrune(ctx, cfg, cloner.GetOSWrapper(), store, discovered, errorsCounter.GetErrors() > 0)
+	}
+
+	report.summary = &RunSummary{
+		RunID:       journal.GetRunID(),
+		StartedAt:   startedAt,
+		FinishedAt:  time.Now().UTC(),
+		Interrupted: interrupted,
+		Cloned:      counter.GetStatusCount(SyncStatusCloned),
+		Updated:     counter.GetStatusCount(SyncStatusUpdated),
+		Unchanged:   counter.GetStatusCount(SyncStatusUnchanged),
+		Failed:      counter.GetStatusCount(SyncStatusFailed),
+		FetchErrors: errorsCounter.GetErrors(),
+		APIRequests: gitlabClient.GetRequests(),
+	}
+	store.SetLastRun(report.summary)
+
+	if err := store.Save(); err != nil {
+		log.Println(err)
+	}
+
+	log.Println("Processing completed, run ID:", journal.GetRunID())
 	_, completed, success, errors := counter.GetStats()
 	log.Println("Total projects processed:", completed)
 	log.Println("Successful:", success)
+	log.Println("  Cloned:", counter.GetStatusCount(SyncStatusCloned))
+	log.Println("  Updated:", counter.GetStatusCount(SyncStatusUpdated))
+	log.Println("  Unchanged:", counter.GetStatusCount(SyncStatusUnchanged))
 	log.Println("Errors:", errors)
 
+	log.Println("API requests:", gitlabClient.GetRequests())
+
+	if _, resumed, _, _ := resumedCounter.GetStats(); resumed > 0 {
+		log.Println("Finished in previous attempts:", resumed)
+	}
+
 	fetchErrors := errorsCounter.GetErrors()
 	if fetchErrors > 0 {
 		log.Println("Errors occurred while fetching groups or projects:", fetchErrors)
 	}
 
-	return nil
+	if interrupted {
+		return ErrorInterrupted
+	}
+
+	return checkRunResult(cfg, errors, completed, fetchErrors)
+}
+
+// runPrune lists local repositories which do not belong to any discovered project and prunes them according to the prune mode.
+// Nothing is pruned when discovery failed or found no projects, as the missing projects could still exist on GitLab.
+func runPrune(ctx context.Context, cfg *config.Config, osWrapper OSWrapper, store *StateStore, discovered map[string]struct{}, fetchFailed bool) {
+	log.Println()
+
+	if fetchFailed {
+		log.Println("Prune skipped: errors occurred while fetching groups or projects")
+		return
+	}
+
+	if len(discovered) == 0 {
+		log.Println("Prune skipped: no projects discovered")
+		return
+	}
+
+	orphans, err := findOrphanedRepositories(cfg, discovered)
+	if err != nil {
+		log.Println("Prune failed:", err)
+		return
+	}
+
+	log.Println("Orphaned repositories:", len(orphans))
+	for _, orphan := range orphans {
+		log.Println("\t" + orphan)
+	}
+
+	now := time.Now()
+
+	pruned, err := pruneRepositories(ctx, osWrapper, cfg, orphans, now)
+	for _, path := range pruned {
+		store.RemoveLocalPath(path)
+	}
+	if err != nil {
+		log.Println(err)
+	}
+
+	switch cfg.GetPruneMode() {
+	case config.PruneModeQuarantine:
+		log.Println("Moved to quarantine:", len(pruned), "into", cfg.GetQuarantineDir())
+
+		purged, err := purgeQuarantine(osWrapper, cfg, now)
+		for _, path := range purged {
+			log.Println("Purged expired quarantine:", path)
+		}
+		if err != nil {
+			log.Println(err)
+		}
+	case config.PruneModeDelete:
+		log.Println("Deleted:", len(pruned))
+	}
 }
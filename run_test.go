@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJoinInstanceErrors(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name      string
+		runs      []*instanceRun
+		instances int
+		expected  string
+	}{
+		{
+			name:      "single instance",
+			runs:      []*instanceRun{{err: failed}},
+			instances: 1,
+			expected:  "failed",
+		},
+		{
+			name:      "single instance interrupted before its run",
+			instances: 1,
+			expected:  ErrorInterrupted.Error(),
+		},
+		{
+			name:      "several instances",
+			runs:      []*instanceRun{{name: "public"}, {name: "internal", err: failed}},
+			instances: 2,
+			expected:  "instance internal: failed",
+		},
+		{
+			name:      "several instances interrupted between runs",
+			runs:      []*instanceRun{{name: "public", err: failed}},
+			instances: 2,
+			expected:  "instance public: failed\n" + ErrorInterrupted.Error(),
+		},
+		{
+			name:      "several instances interrupted during a run",
+			runs:      []*instanceRun{{name: "public", err: ErrorInterrupted}},
+			instances: 2,
+			expected:  "instance public: " + ErrorInterrupted.Error(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := joinInstanceErrors(test.runs, test.instances)
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("expected error %q, got %v", test.expected, err)
+			}
+		})
+	}
+
+	if err := joinInstanceErrors([]*instanceRun{{name: "public"}, {name: "internal"}}, 2); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
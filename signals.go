@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// withShutdownSignals returns a context which is canceled on the first SIGINT or SIGTERM,
+// so in-flight clones are aborted and the run is finished gracefully.
+// The second signal terminates the process immediately with the exit function.
+func withShutdownSignals(parent context.Context, exit func(code int)) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-done:
+			return
+		case sig := <-signals:
+			log.Printf("Received %s, shutting down gracefully, send it again to force exit\n", sig)
+			cancel()
+		}
+
+		select {
+		case <-done:
+			return
+		case sig := <-signals:
+			log.Printf("Received %s again, exiting immediately\n", sig)
+			exit(ExitCodeInterrupted)
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+	}
+}
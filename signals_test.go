@@ -0,0 +1,62 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWithShutdownSignals(t *testing.T) {
+	exitCodes := make(chan int, 1)
+
+	ctx, stop := withShutdownSignals(context.Background(), func(code int) {
+		exitCodes <- code
+	})
+	defer stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected context to be canceled by the first signal")
+	}
+
+	select {
+	case code := <-exitCodes:
+		t.Fatalf("unexpected exit with code %d after the first signal", code)
+	default:
+	}
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case code := <-exitCodes:
+		if code != ExitCodeInterrupted {
+			t.Errorf("expected exit code %d, got %d", ExitCodeInterrupted, code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected exit after the second signal")
+	}
+}
+
+func TestWithShutdownSignals_Stop(t *testing.T) {
+	ctx, stop := withShutdownSignals(context.Background(), func(code int) {
+		t.Errorf("unexpected exit with code %d", code)
+	})
+
+	stop()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected context to be canceled by stop")
+	}
+}
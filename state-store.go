@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const stateFileVersion = 1
+
+// activityUpdateInterval is how often GitLab updates the last activity of a project at most,
+// a push within it after earlier activity leaves the last activity unchanged.
+const activityUpdateInterval = time.Hour
+
+// ProjectState is the persisted sync state of a project.
+type ProjectState struct {
+	ID                int               `json:"id"`
+	PathWithNamespace string            `json:"path_with_namespace"`
+	LocalPath         string            `json:"local_path"`
+	LastActivityAt    *time.Time        `json:"last_activity_at,omitempty"`
+	Refs              map[string]string `json:"refs,omitempty"`
+	LastStatus        string            `json:"last_status"`
+	LastError         string            `json:"last_error,omitempty"`
+	LastSyncAt        time.Time         `json:"last_sync_at"`
+	LastSuccessAt     *time.Time        `json:"last_success_at,omitempty"`
+}
+
+// RunSummary is the persisted statistics of a run.
+type RunSummary struct {
+	RunID       string    `json:"run_id"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Interrupted bool      `json:"interrupted"`
+	Cloned      uint32    `json:"cloned"`
+	Updated     uint32    `json:"updated"`
+	Unchanged   uint32    `json:"unchanged"`
+	Failed      uint32    `json:"failed"`
+	FetchErrors uint32    `json:"fetch_errors"`
+	APIRequests int64     `json:"api_requests"`
+}
+
+type stateFile struct {
+	Version   int                   `json:"version"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	LastRun   *RunSummary           `json:"last_run,omitempty"`
+	Projects  map[int]*ProjectState `json:"projects"`
+}
+
+// StateStore keeps the sync state of projects between runs, keyed by GitLab project ID.
+type StateStore struct {
+	path     string
+	lastRun  *RunSummary
+	projects map[int]*ProjectState
+	mutex    *sync.Mutex
+}
+
+func NewStateStore(path string) *StateStore {
+	return &StateStore{
+		path:     path,
+		projects: map[int]*ProjectState{},
+		mutex:    &sync.Mutex{},
+	}
+}
+
+func (s *StateStore) GetPath() string {
+	return s.path
+}
+
+// Load reads the state file, a missing file is treated as an empty state.
+func (s *StateStore) Load() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return &ErrorStateStore{s.path, err}
+	}
+
+	state := &stateFile{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return &ErrorStateStore{s.path, err}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastRun = state.LastRun
+	s.projects = map[int]*ProjectState{}
+	for id, project := range state.Projects {
+		if project != nil {
+			s.projects[id] = project
+		}
+	}
+
+	return nil
+}
+
+// Save writes the state to a temporary file next to the state file and renames it into place,
+// so the state file is never left partially written.
+func (s *StateStore) Save() error {
+	s.mutex.Lock()
+	data, err := json.MarshalIndent(&stateFile{
+		Version:   stateFileVersion,
+		UpdatedAt: time.Now().UTC(),
+		LastRun:   s.lastRun,
+		Projects:  s.projects,
+	}, "", "  ")
+	s.mutex.Unlock()
+
+	if err != nil {
+		return &ErrorStateStore{s.path, err}
+	}
+
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return &ErrorStateStore{s.path, err}
+	}
+
+	return nil
+}
+
+// Get returns a copy of the state of the project.
+func (s *StateStore) Get(projectID int) (ProjectState, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, ok := s.projects[projectID]
+	if !ok {
+		return ProjectState{}, false
+	}
+
+	result := *state
+	result.Refs = maps.Clone(state.Refs)
+
+	return result, true
+}
+
+// IsUnchanged reports whether the project was successfully synced to the same local path
+// and has had no activity on GitLab since then. The last sync must have been more than activityUpdateInterval
+// after the last activity, otherwise later pushes could have left the last activity unchanged.
+func (s *StateStore) IsUnchanged(project *Project, localPath string) bool {
+	if project == nil || project.lastActivityAt == nil {
+		return false
+	}
+
+	state, ok := s.Get(project.id)
+	if !ok || state.LastActivityAt == nil {
+		return false
+	}
+
+	return state.LastStatus != SyncStatusFailed.String() &&
+		state.LocalPath == localPath &&
+		state.LastActivityAt.Equal(*project.lastActivityAt) &&
+		state.LastSuccessAt != nil &&
+		state.LastSuccessAt.Sub(*state.LastActivityAt) > activityUpdateInterval
+}
+
+// Record stores the outcome of syncing the project.
+// Refs and last activity are kept from the previous state when the sync failed or refs are not known.
+func (s *StateStore) Record(project *Project, localPath string, status SyncStatus, refs map[string]string, syncErr error) {
+	if project == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now().UTC()
+
+	state, ok := s.projects[project.id]
+	if !ok {
+		state = &ProjectState{ID: project.id}
+		s.projects[project.id] = state
+	}
+
+	state.PathWithNamespace = project.pathWithNamespace
+	state.LastStatus = status.String()
+	state.LastSyncAt = now
+
+	if syncErr != nil || status == SyncStatusFailed {
+		state.LastError = ""
+		if syncErr != nil {
+			state.LastError = syncErr.Error()
+		}
+		return
+	}
+
+	state.LocalPath = localPath
+	state.LastActivityAt = project.lastActivityAt
+	state.LastError = ""
+	state.LastSuccessAt = &now
+	if refs != nil {
+		state.Refs = refs
+	}
+}
+
+// SetLastRun stores the statistics of the current run.
+func (s *StateStore) SetLastRun(summary *RunSummary) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastRun = summary
+}
+
+// GetLastRun returns the statistics of the last stored run.
+func (s *StateStore) GetLastRun() *RunSummary {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.lastRun
+}
+
+// RemoveLocalPath forgets the projects stored at the local path.
+func (s *StateStore) RemoveLocalPath(localPath string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	localPath = filepath.Clean(localPath)
+	for id, state := range s.projects {
+		if filepath.Clean(state.LocalPath) == localPath {
+			delete(s.projects, id)
+		}
+	}
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := file.Name()
+
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateStore_LoadMissingFile(t *testing.T) {
+	store := NewStateStore(filepath.Join(t.TempDir(), "missing.json"))
+	if err := store.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := store.Get(1); ok {
+		t.Error("expected empty state")
+	}
+}
+
+func TestStateStore_LoadCorruptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := NewStateStore(path).Load()
+
+	var storeErr *ErrorStateStore
+	if !errors.As(err, &storeErr) {
+		t.Fatalf("expected ErrorStateStore, got %v", err)
+	}
+}
+
+func TestStateStore_SaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "state.json")
+	lastActivity := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	store := NewStateStore(path)
+	store.Record(
+		&Project{id: 7, pathWithNamespace: "group/project", lastActivityAt: &lastActivity},
+		"repos/group/project",
+		SyncStatusCloned,
+		map[string]string{"refs/heads/main": "aaa"},
+		nil,
+	)
+
+	store.SetLastRun(&RunSummary{RunID: "run", Cloned: 1, Interrupted: true})
+
+	if err := store.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the state file to be left, got %d entries", len(entries))
+	}
+
+	loaded := NewStateStore(path)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	state, ok := loaded.Get(7)
+	if !ok {
+		t.Fatal("expected project state to be loaded")
+	}
+	if state.LocalPath != "repos/group/project" ||
+		state.LastStatus != SyncStatusCloned.String() ||
+		state.Refs["refs/heads/main"] != "aaa" ||
+		state.LastActivityAt == nil || !state.LastActivityAt.Equal(lastActivity) ||
+		state.LastSuccessAt == nil {
+		t.Errorf("unexpected state loaded: %+v", state)
+	}
+
+	lastRun := loaded.GetLastRun()
+	if lastRun == nil || lastRun.RunID != "run" || lastRun.Cloned != 1 || !lastRun.Interrupted {
+		t.Errorf("unexpected last run loaded: %+v", lastRun)
+	}
+}
+
+func TestStateStore_RecordFailureKeepsLastSuccess(t *testing.T) {
+	lastActivity := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	project := &Project{id: 1, pathWithNamespace: "group/project", lastActivityAt: &lastActivity}
+
+	store := NewStateStore("state.json")
+	store.Record(project, "group/project", SyncStatusCloned, map[string]string{"refs/heads/main": "aaa"}, nil)
+
+	newActivity := lastActivity.Add(time.Hour)
+	project.lastActivityAt = &newActivity
+	store.Record(project, "group/project", SyncStatusFailed, nil, errors.New("fail"))
+
+	state, _ := store.Get(project.id)
+	if state.LastStatus != SyncStatusFailed.String() || state.LastError != "fail" {
+		t.Errorf("expected failure to be recorded, got %+v", state)
+	}
+	if state.LastSuccessAt == nil || state.Refs["refs/heads/main"] != "aaa" {
+		t.Errorf("expected last successful sync to be kept, got %+v", state)
+	}
+	if !state.LastActivityAt.Equal(lastActivity) {
+		t.Errorf("expected last activity of the successful sync, got %v", state.LastActivityAt)
+	}
+}
+
+func TestStateStore_IsUnchanged(t *testing.T) {
+	lastActivity := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	newActivity := lastActivity.Add(time.Hour)
+
+	store := NewStateStore("state.json")
+	store.Record(&Project{id: 1, lastActivityAt: &lastActivity}, "group/project", SyncStatusCloned, nil, nil)
+	store.Record(&Project{id: 2, lastActivityAt: &lastActivity}, "group/failed", SyncStatusFailed, nil, errors.New("fail"))
+	recentActivity := time.Now().Add(-10 * time.Minute)
+	store.Record(&Project{id: 4, lastActivityAt: &recentActivity}, "group/recent", SyncStatusCloned, nil, nil)
+
+	tests := []struct {
+		name      string
+		project   *Project
+		localPath string
+		expected  bool
+	}{
+		{"nil project", nil, "group/project", false},
+		{"unknown project", &Project{id: 3, lastActivityAt: &lastActivity}, "group/project", false},
+		{"no activity date", &Project{id: 1}, "group/project", false},
+		{"same activity", &Project{id: 1, lastActivityAt: &lastActivity}, "group/project", true},
+		{"new activity", &Project{id: 1, lastActivityAt: &newActivity}, "group/project", false},
+		{"different local path", &Project{id: 1, lastActivityAt: &lastActivity}, "group/renamed", false},
+		{"last sync failed", &Project{id: 2, lastActivityAt: &lastActivity}, "group/failed", false},
+		{"synced soon after activity", &Project{id: 4, lastActivityAt: &recentActivity}, "group/recent", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := store.IsUnchanged(test.project, test.localPath); result != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestStateStore_RemoveLocalPath(t *testing.T) {
+	store := NewStateStore("state.json")
+	store.Record(&Project{id: 1}, "repos/group/project", SyncStatusCloned, nil, nil)
+	store.Record(&Project{id: 2}, "repos/group/other", SyncStatusCloned, nil, nil)
+
+	store.RemoveLocalPath("./repos/group/project")
+
+	if _, ok := store.Get(1); ok {
+		t.Error("expected project stored at the removed path to be forgotten")
+	}
+	if _, ok := store.Get(2); !ok {
+		t.Error("expected other project to be kept")
+	}
+}
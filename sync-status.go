@@ -0,0 +1,34 @@
+package main
+
+// SyncStatus describes what happened to a project during a run.
+type SyncStatus int
+
+const (
+	SyncStatusFailed SyncStatus = iota
+	SyncStatusCloned
+	SyncStatusUpdated
+	SyncStatusUnchanged
+)
+
+func (s SyncStatus) String() string {
+	switch s {
+	case SyncStatusCloned:
+		return "cloned"
+	case SyncStatusUpdated:
+		return "updated"
+	case SyncStatusUnchanged:
+		return "unchanged"
+	default:
+		return "failed"
+	}
+}
+
+func parseSyncStatus(value string) SyncStatus {
+	for _, status := range []SyncStatus{SyncStatusCloned, SyncStatusUpdated, SyncStatusUnchanged} {
+		if status.String() == value {
+			return status
+		}
+	}
+
+	return SyncStatusFailed
+}
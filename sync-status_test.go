@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestSyncStatus_String(t *testing.T) {
+	tests := map[SyncStatus]string{
+		SyncStatusFailed:    "failed",
+		SyncStatusCloned:    "cloned",
+		SyncStatusUpdated:   "updated",
+		SyncStatusUnchanged: "unchanged",
+		SyncStatus(42):      "failed",
+	}
+
+	for status, want := range tests {
+		if got := status.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestParseSyncStatus(t *testing.T) {
+	for _, status := range []SyncStatus{SyncStatusFailed, SyncStatusCloned, SyncStatusUpdated, SyncStatusUnchanged} {
+		if got := parseSyncStatus(status.String()); got != status {
+			t.Errorf("got %s, want %s", got, status)
+		}
+	}
+
+	if got := parseSyncStatus("unknown"); got != SyncStatusFailed {
+		t.Errorf("got %s, want %s", got, SyncStatusFailed)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"sync"
 )
 
@@ -87,3 +88,47 @@ func teeChan[T any](ctx context.Context, in <-chan T, amountChans int) []chan T
 
 	return chans
 }
+
+// idSet is a set of GitLab IDs safe for concurrent use, it lets concurrent discovery goroutines
+// claim a group or project so it is processed only once.
+type idSet struct {
+	mu  sync.Mutex
+	ids map[int]struct{}
+}
+
+func newIDSet() *idSet {
+	return &idSet{
+		ids: map[int]struct{}{},
+	}
+}
+
+// add adds the ID to the set and reports whether it was not there yet.
+func (s *idSet) add(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.ids[id]; ok {
+		return false
+	}
+	s.ids[id] = struct{}{}
+
+	return true
+}
+
+// formatSize returns the size in bytes with a binary unit, e.g. 1.5 GiB.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size)
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB"}
+	index := -1
+	for value >= unit && index < len(units)-1 {
+		value /= unit
+		index++
+	}
+
+	return fmt.Sprintf("%.1f %s", value, units[index])
+}
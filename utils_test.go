@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 )
@@ -112,3 +113,49 @@ func TestTeeChan_ContextCancelClosesChannels(t *testing.T) {
 		}
 	}
 }
+
+func TestIDSet_ConcurrentAdd(t *testing.T) {
+	set := newIDSet()
+
+	added := make(chan bool, 100)
+	wg := &sync.WaitGroup{}
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			added <- set.add(42)
+		}()
+	}
+	wg.Wait()
+	close(added)
+
+	count := 0
+	for ok := range added {
+		if ok {
+			count++
+		}
+	}
+
+	if count != 1 {
+		t.Errorf("expected the ID to be added once, got %d", count)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size     int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+
+	for _, test := range tests {
+		if result := formatSize(test.size); result != test.expected {
+			t.Errorf("expected %s for %d, got %s", test.expected, test.size, result)
+		}
+	}
+}